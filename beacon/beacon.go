@@ -0,0 +1,60 @@
+// Package beacon is a minimal client of the beacon node API, covering what
+// preconfirmation flow needs: head slot, genesis, chain spec and blocks.
+package beacon
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// ErrBlockNotFound is returned by [BeaconClient.BlockBySlot], when slot has no
+// block, either because it was missed or isn't proposed yet.
+var ErrBlockNotFound = errors.New("beacon block not found")
+
+// BeaconClient is the subset of the beacon node API used by luban.
+type BeaconClient interface {
+	// HeadSlot returns slot of the current head.
+	HeadSlot(ctx context.Context) (uint64, error)
+	// Genesis returns genesis of the beacon chain.
+	Genesis(ctx context.Context) (*Genesis, error)
+	// Spec returns chain spec values luban depends on.
+	Spec(ctx context.Context) (*Spec, error)
+	// BlockBySlot returns block proposed in the slot. It fails with
+	// [ErrBlockNotFound], if there is none.
+	BlockBySlot(ctx context.Context, slot uint64) (*Block, error)
+}
+
+type Genesis struct {
+	// Time is genesis time in unix seconds
+	Time           uint64
+	ValidatorsRoot common.Hash
+	ForkVersion    hexutil.Bytes
+}
+
+// StartTime returns start of the genesis slot.
+func (g *Genesis) StartTime() time.Time {
+	return time.Unix(int64(g.Time), 0)
+}
+
+type Spec struct {
+	SecondsPerSlot uint64
+	SlotsPerEpoch  uint64
+}
+
+// SlotDuration returns SECONDS_PER_SLOT as a duration.
+func (s *Spec) SlotDuration() time.Duration {
+	return time.Duration(s.SecondsPerSlot) * time.Second
+}
+
+// Block is the beacon block with execution payload summary.
+type Block struct {
+	Slot          uint64 `json:"slot"`
+	ProposerIndex uint64 `json:"proposer_index"`
+	// BlockNumber and BlockHash identify execution payload of the block
+	BlockNumber uint64      `json:"block_number"`
+	BlockHash   common.Hash `json:"block_hash"`
+}
@@ -0,0 +1,125 @@
+package beacon
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	TopicHead  = "head"
+	TopicBlock = "block"
+)
+
+// ErrStreamClosed is returned by [EventStream.Next], when beacon node closes
+// the stream.
+var ErrStreamClosed = errors.New("beacon event stream closed")
+
+// Event is either head or block event of the beacon node event stream.
+type Event struct {
+	Topic string
+	Slot  uint64
+	Block common.Hash
+}
+
+// EventSource is [BeaconClient], which can stream events.
+type EventSource interface {
+	BeaconClient
+
+	Subscribe(ctx context.Context, topics ...string) (*EventStream, error)
+}
+
+var _ EventSource = (*HTTPClient)(nil)
+
+// EventStream reads server-sent events from `/eth/v1/events`.
+type EventStream struct {
+	body    io.ReadCloser
+	scanner *bufio.Scanner
+}
+
+// Subscribe opens event stream for the topics. It returns once beacon node
+// accepted the subscription. Request timeout doesn't apply to the stream,
+// it lives until ctx is done or stream is closed.
+func (c *HTTPClient) Subscribe(ctx context.Context, topics ...string) (*EventStream, error) {
+	path := "/eth/v1/events?topics=" + strings.Join(topics, ",")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url+path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	for key, values := range c.headers {
+		req.Header[key] = values
+	}
+	req.Header.Set("Accept", "text/event-stream")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to subscribe to events: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		return nil, newAPIError(path, resp)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 4096), 1<<20)
+	return &EventStream{body: resp.Body, scanner: scanner}, nil
+}
+
+// Next blocks until the next head or block event. Events of other topics are
+// skipped.
+func (s *EventStream) Next() (Event, error) {
+	var topic string
+	var data strings.Builder
+	for s.scanner.Scan() {
+		line := s.scanner.Text()
+		switch {
+		case line == "":
+			// Blank line dispatches the event
+			if topic == TopicHead || topic == TopicBlock {
+				return parseEvent(topic, data.String())
+			}
+			topic = ""
+			data.Reset()
+		case strings.HasPrefix(line, ":"):
+			// Comment, usually keep-alive
+		case strings.HasPrefix(line, "event:"):
+			topic = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			if data.Len() > 0 {
+				data.WriteByte('\n')
+			}
+			data.WriteString(strings.TrimSpace(strings.TrimPrefix(line, "data:")))
+		}
+	}
+	if err := s.scanner.Err(); err != nil {
+		return Event{}, fmt.Errorf("failed to read event stream: %w", err)
+	}
+	return Event{}, ErrStreamClosed
+}
+
+func (s *EventStream) Close() error {
+	return s.body.Close()
+}
+
+func parseEvent(topic, data string) (Event, error) {
+	var parsed struct {
+		Slot  string      `json:"slot"`
+		Block common.Hash `json:"block"`
+	}
+	if err := json.Unmarshal([]byte(data), &parsed); err != nil {
+		return Event{}, fmt.Errorf("failed to decode %s event: %w", topic, err)
+	}
+	slot, err := strconv.ParseUint(parsed.Slot, 10, 64)
+	if err != nil {
+		return Event{}, fmt.Errorf("invalid %s event slot value: %w", topic, err)
+	}
+	return Event{Topic: topic, Slot: slot, Block: parsed.Block}, nil
+}
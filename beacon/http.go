@@ -0,0 +1,264 @@
+package beacon
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// DefaultTimeout is the timeout of a single request, if none is set with
+// [WithTimeout].
+const DefaultTimeout = 10 * time.Second
+
+// APIError is the error returned by beacon node as `{code, message}` body.
+type APIError struct {
+	Path       string
+	StatusCode int
+	Message    string
+}
+
+func newAPIError(path string, resp *http.Response) *APIError {
+	body, _ := io.ReadAll(resp.Body)
+	apiErr := &APIError{Path: path, StatusCode: resp.StatusCode}
+	var parsed struct {
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(body, &parsed); err == nil && parsed.Message != "" {
+		apiErr.Message = parsed.Message
+	} else {
+		apiErr.Message = strings.TrimSpace(string(body))
+	}
+	return apiErr
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("beacon request %s failed with status %d: %s", e.Path, e.StatusCode, e.Message)
+}
+
+// Option configures [HTTPClient].
+type Option func(*HTTPClient)
+
+// WithTimeout sets timeout of every request, usually to `NetworkTimeout` of
+// txmgr config. Zero disables it, leaving only ctx deadline.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *HTTPClient) {
+		c.timeout = timeout
+	}
+}
+
+// WithHeader adds header to every request, e.g. for authentication.
+func WithHeader(key, value string) Option {
+	return func(c *HTTPClient) {
+		c.headers.Add(key, value)
+	}
+}
+
+// WithHTTPClient replaces default http client.
+func WithHTTPClient(client *http.Client) Option {
+	return func(c *HTTPClient) {
+		c.client = client
+	}
+}
+
+// HTTPClient implements [BeaconClient] over beacon node REST API. Genesis and
+// spec are cached after the first successful request.
+type HTTPClient struct {
+	url     string
+	client  *http.Client
+	timeout time.Duration
+	headers http.Header
+
+	mu      sync.Mutex
+	genesis *Genesis
+	spec    *Spec
+}
+
+var _ BeaconClient = (*HTTPClient)(nil)
+
+func NewClient(url string, opts ...Option) *HTTPClient {
+	c := &HTTPClient{
+		url:     strings.TrimSuffix(url, "/"),
+		client:  http.DefaultClient,
+		timeout: DefaultTimeout,
+		headers: make(http.Header),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// URL returns base url of the beacon node.
+func (c *HTTPClient) URL() string {
+	return c.url
+}
+
+func (c *HTTPClient) HeadSlot(ctx context.Context) (uint64, error) {
+	var resp struct {
+		Data struct {
+			HeadSlot string `json:"head_slot"`
+		} `json:"data"`
+	}
+	if err := c.get(ctx, "/eth/v1/node/syncing", &resp); err != nil {
+		return 0, err
+	}
+
+	headSlot, err := strconv.ParseUint(resp.Data.HeadSlot, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid head_slot value: %w", err)
+	}
+	return headSlot, nil
+}
+
+func (c *HTTPClient) Genesis(ctx context.Context) (*Genesis, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.genesis != nil {
+		return c.genesis, nil
+	}
+
+	var resp struct {
+		Data struct {
+			GenesisTime           string        `json:"genesis_time"`
+			GenesisValidatorsRoot common.Hash   `json:"genesis_validators_root"`
+			GenesisForkVersion    hexutil.Bytes `json:"genesis_fork_version"`
+		} `json:"data"`
+	}
+	if err := c.get(ctx, "/eth/v1/beacon/genesis", &resp); err != nil {
+		return nil, err
+	}
+
+	genesisTime, err := strconv.ParseUint(resp.Data.GenesisTime, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid genesis_time value: %w", err)
+	}
+	c.genesis = &Genesis{
+		Time:           genesisTime,
+		ValidatorsRoot: resp.Data.GenesisValidatorsRoot,
+		ForkVersion:    resp.Data.GenesisForkVersion,
+	}
+	return c.genesis, nil
+}
+
+func (c *HTTPClient) Spec(ctx context.Context) (*Spec, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.spec != nil {
+		return c.spec, nil
+	}
+
+	var resp struct {
+		Data map[string]string `json:"data"`
+	}
+	if err := c.get(ctx, "/eth/v1/config/spec", &resp); err != nil {
+		return nil, err
+	}
+
+	secondsPerSlot, err := specValue(resp.Data, "SECONDS_PER_SLOT")
+	if err != nil {
+		return nil, err
+	}
+	if secondsPerSlot == 0 {
+		return nil, errors.New("invalid SECONDS_PER_SLOT value: 0")
+	}
+	slotsPerEpoch, err := specValue(resp.Data, "SLOTS_PER_EPOCH")
+	if err != nil {
+		return nil, err
+	}
+	c.spec = &Spec{
+		SecondsPerSlot: secondsPerSlot,
+		SlotsPerEpoch:  slotsPerEpoch,
+	}
+	return c.spec, nil
+}
+
+func specValue(spec map[string]string, key string) (uint64, error) {
+	s, ok := spec[key]
+	if !ok {
+		return 0, fmt.Errorf("spec has no %s value", key)
+	}
+	v, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s value: %w", key, err)
+	}
+	return v, nil
+}
+
+func (c *HTTPClient) BlockBySlot(ctx context.Context, slot uint64) (*Block, error) {
+	var resp struct {
+		Data struct {
+			Message struct {
+				Slot          string `json:"slot"`
+				ProposerIndex string `json:"proposer_index"`
+				Body          struct {
+					ExecutionPayload struct {
+						BlockNumber string      `json:"block_number"`
+						BlockHash   common.Hash `json:"block_hash"`
+					} `json:"execution_payload"`
+				} `json:"body"`
+			} `json:"message"`
+		} `json:"data"`
+	}
+	err := c.get(ctx, fmt.Sprintf("/eth/v2/beacon/blocks/%d", slot), &resp)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w: slot %d", ErrBlockNotFound, slot)
+	} else if err != nil {
+		return nil, err
+	}
+
+	msg := resp.Data.Message
+	block := &Block{BlockHash: msg.Body.ExecutionPayload.BlockHash}
+	if block.Slot, err = strconv.ParseUint(msg.Slot, 10, 64); err != nil {
+		return nil, fmt.Errorf("invalid slot value: %w", err)
+	}
+	if block.ProposerIndex, err = strconv.ParseUint(msg.ProposerIndex, 10, 64); err != nil {
+		return nil, fmt.Errorf("invalid proposer_index value: %w", err)
+	}
+	if block.BlockNumber, err = strconv.ParseUint(msg.Body.ExecutionPayload.BlockNumber, 10, 64); err != nil {
+		return nil, fmt.Errorf("invalid block_number value: %w", err)
+	}
+	return block, nil
+}
+
+func (c *HTTPClient) get(ctx context.Context, path string, out any) error {
+	if c.timeout != 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url+path, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	for key, values := range c.headers {
+		req.Header[key] = values
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to make GET request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return newAPIError(path, resp)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode JSON: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package beacon
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newTestNode(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/eth/v1/node/syncing", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"code":401,"message":"unauthorized"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":{"head_slot":"42","sync_distance":"0","is_syncing":false}}`))
+	})
+	mux.HandleFunc("/eth/v1/beacon/genesis", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"data":{"genesis_time":"1700000000","genesis_validators_root":"0x0000000000000000000000000000000000000000000000000000000000000001","genesis_fork_version":"0x10000038"}}`))
+	})
+	mux.HandleFunc("/eth/v1/config/spec", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"data":{"SECONDS_PER_SLOT":"6","SLOTS_PER_EPOCH":"32","CONFIG_NAME":"devnet"}}`))
+	})
+	mux.HandleFunc("/eth/v2/beacon/blocks/40", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"version":"deneb","data":{"message":{"slot":"40","proposer_index":"7","body":{"execution_payload":{"block_number":"100","block_hash":"0x0000000000000000000000000000000000000000000000000000000000000002"}}}}}`))
+	})
+	mux.HandleFunc("/eth/v2/beacon/blocks/41", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"code":404,"message":"NOT_FOUND: beacon block at slot 41"}`))
+	})
+	mux.HandleFunc("/slow/eth/v1/node/syncing", func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestHTTPClient(t *testing.T) {
+	ctx := context.Background()
+	node := newTestNode(t)
+	bn := NewClient(node.URL+"/", WithHeader("Authorization", "Bearer secret"))
+
+	head, err := bn.HeadSlot(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if head != 42 {
+		t.Fatalf("Wrong head slot. Have %d, want 42", head)
+	}
+
+	genesis, err := bn.Genesis(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if genesis.Time != 1_700_000_000 || genesis.ValidatorsRoot[31] != 1 {
+		t.Fatalf("Wrong genesis: %+v", genesis)
+	}
+
+	spec, err := bn.Spec(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if spec.SlotDuration() != 6*time.Second || spec.SlotsPerEpoch != 32 {
+		t.Fatalf("Wrong spec: %+v", spec)
+	}
+
+	block, err := bn.BlockBySlot(ctx, 40)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if block.Slot != 40 || block.ProposerIndex != 7 || block.BlockNumber != 100 || block.BlockHash[31] != 2 {
+		t.Fatalf("Wrong block: %+v", block)
+	}
+	if _, err := bn.BlockBySlot(ctx, 41); !errors.Is(err, ErrBlockNotFound) {
+		t.Fatalf("Expected %v, have %v", ErrBlockNotFound, err)
+	}
+}
+
+func TestHTTPClientErrors(t *testing.T) {
+	ctx := context.Background()
+	node := newTestNode(t)
+
+	var apiErr *APIError
+	if _, err := NewClient(node.URL).HeadSlot(ctx); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("Expected unauthorized error, have %v", err)
+	}
+	if apiErr.Message != "unauthorized" {
+		t.Fatalf("Wrong error message: %q", apiErr.Message)
+	}
+
+	slow := NewClient(node.URL+"/slow", WithTimeout(50*time.Millisecond))
+	if _, err := slow.HeadSlot(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected %v, have %v", context.DeadlineExceeded, err)
+	}
+
+	cctx, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := NewClient(node.URL, WithTimeout(0)).HeadSlot(cctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected %v, have %v", context.Canceled, err)
+	}
+}
@@ -0,0 +1,94 @@
+package beacon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ethereum-optimism/optimism/op-service/clock"
+)
+
+// ErrInvalidSlotDuration is returned, when slot clock is created with
+// non-positive slot duration.
+var ErrInvalidSlotDuration = errors.New("invalid slot duration")
+
+// SlotClock computes slots locally from genesis time and slot duration, so
+// knowing current slot doesn't cost a request to beacon node.
+type SlotClock struct {
+	genesis      time.Time
+	slotDuration time.Duration
+	clock        clock.Clock
+}
+
+// NewSlotClock creates slot clock. Nil clk means system clock. It fails with
+// [ErrInvalidSlotDuration], unless slot duration is positive.
+func NewSlotClock(genesis time.Time, slotDuration time.Duration, clk clock.Clock) (*SlotClock, error) {
+	if slotDuration <= 0 {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSlotDuration, slotDuration)
+	}
+	if clk == nil {
+		clk = clock.SystemClock
+	}
+	return &SlotClock{
+		genesis:      genesis,
+		slotDuration: slotDuration,
+		clock:        clk,
+	}, nil
+}
+
+// NewSlotClockFromBeacon creates slot clock with genesis time and
+// SECONDS_PER_SLOT fetched from bc. Nil clk means system clock.
+func NewSlotClockFromBeacon(ctx context.Context, bc BeaconClient, clk clock.Clock) (*SlotClock, error) {
+	genesis, err := bc.Genesis(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get genesis: %w", err)
+	}
+	spec, err := bc.Spec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get spec: %w", err)
+	}
+	return NewSlotClock(genesis.StartTime(), spec.SlotDuration(), clk)
+}
+
+// SlotDuration returns duration of a single slot.
+func (c *SlotClock) SlotDuration() time.Duration {
+	return c.slotDuration
+}
+
+// SlotAt returns slot in progress at t. Times before genesis are slot 0.
+func (c *SlotClock) SlotAt(t time.Time) uint64 {
+	if t.Before(c.genesis) {
+		return 0
+	}
+	return uint64(t.Sub(c.genesis) / c.slotDuration)
+}
+
+// Now returns current time of the underlying clock.
+func (c *SlotClock) Now() time.Time {
+	return c.clock.Now()
+}
+
+// CurrentSlot returns slot in progress now.
+func (c *SlotClock) CurrentSlot() uint64 {
+	return c.SlotAt(c.clock.Now())
+}
+
+// SlotStart returns time, when slot starts.
+func (c *SlotClock) SlotStart(slot uint64) time.Time {
+	return c.genesis.Add(time.Duration(slot) * c.slotDuration)
+}
+
+// TimeToSlot returns duration until slot starts. It is negative for slots,
+// which already started.
+func (c *SlotClock) TimeToSlot(slot uint64) time.Duration {
+	return c.SlotStart(slot).Sub(c.clock.Now())
+}
+
+// WaitForSlot blocks until slot starts.
+func (c *SlotClock) WaitForSlot(ctx context.Context, slot uint64) error {
+	if d := c.TimeToSlot(slot); d > 0 {
+		return c.clock.SleepCtx(ctx, d)
+	}
+	return ctx.Err()
+}
@@ -0,0 +1,81 @@
+package beacon
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ethereum-optimism/optimism/op-service/clock"
+)
+
+func TestSlotClock(t *testing.T) {
+	genesis := time.Unix(1_700_000_000, 0)
+	clk := clock.NewDeterministicClock(genesis.Add(-time.Second))
+	slots, err := NewSlotClock(genesis, 12*time.Second, clk)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if slot := slots.CurrentSlot(); slot != 0 {
+		t.Fatalf("Wrong slot before genesis. Have %d, want 0", slot)
+	}
+	clk.AdvanceTime(25 * time.Second)
+	if slot := slots.CurrentSlot(); slot != 2 {
+		t.Fatalf("Wrong current slot. Have %d, want 2", slot)
+	}
+	if start := slots.SlotStart(3); !start.Equal(genesis.Add(36 * time.Second)) {
+		t.Fatalf("Wrong slot start: %v", start)
+	}
+	if d := slots.TimeToSlot(3); d != 12*time.Second {
+		t.Fatalf("Wrong time to slot. Have %v, want 12s", d)
+	}
+	if d := slots.TimeToSlot(1); d != -12*time.Second {
+		t.Fatalf("Wrong time to started slot. Have %v, want -12s", d)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- slots.WaitForSlot(context.Background(), 3)
+	}()
+	clk.WaitForNewPendingTaskWithTimeout(time.Second)
+	select {
+	case <-done:
+		t.Fatal("WaitForSlot returned before slot start")
+	default:
+	}
+	clk.AdvanceTime(12 * time.Second)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if err := slots.WaitForSlot(context.Background(), 3); err != nil {
+		t.Fatalf("Failed to wait for started slot: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := slots.WaitForSlot(ctx, 10); err != context.Canceled {
+		t.Fatalf("Expected %v, have %v", context.Canceled, err)
+	}
+
+	if _, err := NewSlotClock(genesis, 0, clk); !errors.Is(err, ErrInvalidSlotDuration) {
+		t.Fatalf("Expected %v, have %v", ErrInvalidSlotDuration, err)
+	}
+}
+
+func TestSlotClockFromBeacon(t *testing.T) {
+	node := newTestNode(t)
+	genesis := time.Unix(1_700_000_000, 0)
+	clk := clock.NewDeterministicClock(genesis.Add(13 * time.Second))
+
+	slots, err := NewSlotClockFromBeacon(context.Background(), NewClient(node.URL), clk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if slots.SlotDuration() != 6*time.Second {
+		t.Fatalf("Wrong slot duration. Have %v, want 6s", slots.SlotDuration())
+	}
+	if slot := slots.CurrentSlot(); slot != 2 {
+		t.Fatalf("Wrong current slot. Have %d, want 2", slot)
+	}
+}
@@ -0,0 +1,236 @@
+package beacon
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+)
+
+const (
+	// DefaultPollInterval is the interval of head slot polling, when event
+	// stream is down.
+	DefaultPollInterval = time.Second
+
+	minReconnectBackoff = time.Second
+	maxReconnectBackoff = 30 * time.Second
+)
+
+// HeadWaiter can block until beacon chain reaches a slot.
+type HeadWaiter interface {
+	// WaitForHead blocks until head slot is at least slot and returns the head.
+	WaitForHead(ctx context.Context, slot uint64) (uint64, error)
+}
+
+// WaitForHead blocks until head slot of bc is at least slot. It uses
+// [HeadWaiter], if bc implements it, otherwise polls head slot every
+// interval.
+func WaitForHead(ctx context.Context, bc BeaconClient, slot uint64, interval time.Duration) (uint64, error) {
+	if w, ok := bc.(HeadWaiter); ok {
+		return w.WaitForHead(ctx, slot)
+	}
+
+	for {
+		head, err := bc.HeadSlot(ctx)
+		if err != nil {
+			return 0, err
+		}
+		if head >= slot {
+			return head, nil
+		}
+		select {
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
+
+// HeadWatcher follows head and block events of the beacon node and serves
+// head slot from them. When the stream is down, it reconnects with
+// exponential backoff and falls back to polling head slot in the meantime.
+//
+// HeadWatcher implements [BeaconClient] and [HeadWaiter], other methods are
+// passed through to the source.
+type HeadWatcher struct {
+	EventSource
+
+	l            log.Logger
+	pollInterval time.Duration
+	minBackoff   time.Duration
+	maxBackoff   time.Duration
+
+	mu        sync.Mutex
+	head      uint64
+	connected bool
+	// updated is closed and replaced, when head or connection state changes
+	updated chan struct{}
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+var (
+	_ BeaconClient = (*HeadWatcher)(nil)
+	_ HeadWaiter   = (*HeadWatcher)(nil)
+)
+
+// NewHeadWatcher creates watcher of src events. Zero pollInterval means
+// [DefaultPollInterval]. It has to be started with [HeadWatcher.Start].
+func NewHeadWatcher(l log.Logger, src EventSource, pollInterval time.Duration) *HeadWatcher {
+	if pollInterval == 0 {
+		pollInterval = DefaultPollInterval
+	}
+	return &HeadWatcher{
+		EventSource:  src,
+		l:            l,
+		pollInterval: pollInterval,
+		minBackoff:   minReconnectBackoff,
+		maxBackoff:   maxReconnectBackoff,
+		updated:      make(chan struct{}),
+	}
+}
+
+// Start subscribes to the event stream in background until [HeadWatcher.Close].
+func (w *HeadWatcher) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	w.cancel = cancel
+	w.done = make(chan struct{})
+	go w.loop(ctx)
+}
+
+// Close stops following the event stream.
+func (w *HeadWatcher) Close() {
+	if w.cancel == nil {
+		return
+	}
+	w.cancel()
+	<-w.done
+}
+
+// Connected reports whether event stream is up.
+func (w *HeadWatcher) Connected() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.connected
+}
+
+// HeadSlot returns head slot from the event stream, or polls it from the
+// source if the stream is down.
+func (w *HeadWatcher) HeadSlot(ctx context.Context) (uint64, error) {
+	w.mu.Lock()
+	head, connected := w.head, w.connected
+	w.mu.Unlock()
+	if connected {
+		return head, nil
+	}
+	return w.poll(ctx)
+}
+
+func (w *HeadWatcher) WaitForHead(ctx context.Context, slot uint64) (uint64, error) {
+	for {
+		w.mu.Lock()
+		head, connected, updated := w.head, w.connected, w.updated
+		w.mu.Unlock()
+		if head >= slot {
+			return head, nil
+		}
+
+		var tick <-chan time.Time
+		if !connected {
+			head, err := w.poll(ctx)
+			if err != nil {
+				return 0, err
+			}
+			if head >= slot {
+				return head, nil
+			}
+			tick = time.After(w.pollInterval)
+		}
+
+		select {
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		case <-updated:
+		case <-tick:
+		}
+	}
+}
+
+func (w *HeadWatcher) poll(ctx context.Context) (uint64, error) {
+	head, err := w.EventSource.HeadSlot(ctx)
+	if err != nil {
+		return 0, err
+	}
+	w.update(func() {
+		w.head = max(w.head, head)
+	})
+	return head, nil
+}
+
+func (w *HeadWatcher) update(fn func()) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	fn()
+	close(w.updated)
+	w.updated = make(chan struct{})
+}
+
+func (w *HeadWatcher) loop(ctx context.Context) {
+	defer close(w.done)
+
+	backoff := w.minBackoff
+	for {
+		up, err := w.follow(ctx)
+		w.update(func() {
+			w.connected = false
+		})
+		if ctx.Err() != nil {
+			return
+		}
+		// Stream, which was up, starts backoff over
+		if up {
+			backoff = w.minBackoff
+		}
+		w.l.Warn("Beacon event stream is down, polling head until reconnected", "err", err, "backoff", backoff)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, w.maxBackoff)
+	}
+}
+
+// follow updates head from the event stream until it fails. It reports
+// whether stream was up.
+func (w *HeadWatcher) follow(ctx context.Context) (bool, error) {
+	stream, err := w.Subscribe(ctx, TopicHead, TopicBlock)
+	if err != nil {
+		return false, err
+	}
+	defer stream.Close()
+
+	// Events come only on new blocks, so seed the head
+	head, err := w.EventSource.HeadSlot(ctx)
+	if err != nil {
+		return false, err
+	}
+	w.l.Debug("Subscribed to beacon events", "head", head)
+	w.update(func() {
+		w.head = max(w.head, head)
+		w.connected = true
+	})
+	for {
+		ev, err := stream.Next()
+		if err != nil {
+			return true, err
+		}
+		w.l.Trace("Beacon event", "topic", ev.Topic, "slot", ev.Slot, "block", ev.Block)
+		w.update(func() {
+			w.head = max(w.head, ev.Slot)
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package beacon
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/ethereum-optimism/optimism/op-service/testlog"
+)
+
+// eventNode streams events written to its channel. Stream is dropped, when
+// drop is signalled, and new subscriptions are rejected while down is set.
+type eventNode struct {
+	*httptest.Server
+
+	head   atomic.Uint64
+	polls  atomic.Int64
+	down   atomic.Bool
+	events chan string
+
+	mu   sync.Mutex
+	drop chan struct{}
+}
+
+func (n *eventNode) dropStream() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	close(n.drop)
+	n.drop = make(chan struct{})
+}
+
+func newEventNode(t *testing.T) *eventNode {
+	n := &eventNode{
+		events: make(chan string),
+		drop:   make(chan struct{}),
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/eth/v1/node/syncing", func(w http.ResponseWriter, r *http.Request) {
+		n.polls.Add(1)
+		fmt.Fprintf(w, `{"data":{"head_slot":"%d"}}`, n.head.Load())
+	})
+	mux.HandleFunc("/eth/v1/events", func(w http.ResponseWriter, r *http.Request) {
+		if n.down.Load() || r.URL.Query().Get("topics") != "head,block" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		n.mu.Lock()
+		drop := n.drop
+		n.mu.Unlock()
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case <-drop:
+				return
+			case ev := <-n.events:
+				fmt.Fprint(w, ev)
+				w.(http.Flusher).Flush()
+			}
+		}
+	})
+	n.Server = httptest.NewServer(mux)
+	t.Cleanup(n.Close)
+	return n
+}
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	for i := 0; i < 200; i++ {
+		if cond() {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("Timed out waiting for %s", what)
+}
+
+func TestHeadWatcher(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	node := newEventNode(t)
+	node.head.Store(3)
+
+	watcher := NewHeadWatcher(testlog.Logger(t, log.LevelDebug), NewClient(node.URL), 10*time.Millisecond)
+	watcher.minBackoff = 10 * time.Millisecond
+	watcher.maxBackoff = 50 * time.Millisecond
+	watcher.Start()
+	defer watcher.Close()
+	waitFor(t, "subscription", watcher.Connected)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		node.events <- ": keep-alive\n\n"
+		node.events <- "event: finalized_checkpoint\ndata: {\"block\":\"0x01\"}\n\n"
+		node.events <- "event: block\ndata: {\"slot\":\"4\",\"block\":\"0x0000000000000000000000000000000000000000000000000000000000000004\"}\n\n"
+		node.events <- "event: head\ndata: {\"slot\":\"5\",\"block\":\"0x0000000000000000000000000000000000000000000000000000000000000005\"}\n\n"
+	}()
+	head, err := watcher.WaitForHead(ctx, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wg.Wait()
+	if head != 5 {
+		t.Fatalf("Wrong head. Have %d, want 5", head)
+	}
+	if polls := node.polls.Load(); polls != 1 {
+		t.Fatalf("Head was polled while stream is up. Have %d polls, want 1", polls)
+	}
+
+	// Stream goes down, head is polled
+	node.down.Store(true)
+	node.dropStream()
+	waitFor(t, "disconnect", func() bool { return !watcher.Connected() })
+	node.head.Store(7)
+	if head, err = watcher.WaitForHead(ctx, 7); err != nil || head != 7 {
+		t.Fatalf("Failed to poll head. Have %d, err %v", head, err)
+	}
+
+	// Stream recovers
+	node.down.Store(false)
+	waitFor(t, "reconnect", watcher.Connected)
+	polls := node.polls.Load()
+	if head, err := watcher.HeadSlot(ctx); err != nil || head != 7 {
+		t.Fatalf("Wrong head after reconnect. Have %d, err %v", head, err)
+	}
+	if node.polls.Load() != polls {
+		t.Fatal("Head was polled after reconnect")
+	}
+}
+
+func TestWaitForHeadPolling(t *testing.T) {
+	node := newEventNode(t)
+	node.head.Store(9)
+	bn := NewClient(node.URL)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := WaitForHead(ctx, bn, 10, 10*time.Millisecond); err != context.DeadlineExceeded {
+		t.Fatalf("Expected %v, have %v", context.DeadlineExceeded, err)
+	}
+	if head, err := WaitForHead(context.Background(), bn, 9, 10*time.Millisecond); err != nil || head != 9 {
+		t.Fatalf("Wrong head. Have %d, err %v", head, err)
+	}
+}
@@ -2,12 +2,12 @@ package client
 
 import (
 	"context"
-	"crypto/ecdsa"
-	"encoding/hex"
 	"fmt"
 
 	"github.com/google/uuid"
 
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/crypto"
 
 	internal "github.com/risechain/luban-api/internal/client"
@@ -17,16 +17,17 @@ import (
 type Client struct {
 	*internal.ClientWithResponses
 
-	key *ecdsa.PrivateKey
+	signer   Signer
+	headSlot HeadSlotSource
 }
 
 // FIXME: reexport options
-func NewClient(server string, key *ecdsa.PrivateKey, opts ...internal.ClientOption) (*Client, error) {
+func NewClient(server string, signer Signer, opts ...internal.ClientOption) (*Client, error) {
 	cl, err := internal.NewClientWithResponses(server, opts...)
 	if err != nil {
 		return nil, fmt.Errorf("Failed to make preconf http client: %w", err)
 	}
-	client := Client{ClientWithResponses: cl, key: key}
+	client := Client{ClientWithResponses: cl, signer: signer}
 	return &client, nil
 }
 
@@ -36,7 +37,7 @@ func (cl *Client) GetSlots(ctx context.Context) ([]types.SlotInfo, error) {
 		return []types.SlotInfo{}, fmt.Errorf("Http request for getting slots failed: %w", err)
 	}
 	if resp.JSON200 == nil {
-		return []types.SlotInfo{}, fmt.Errorf("GetSlots return code %v: %s", resp.Status(), string(resp.Body))
+		return []types.SlotInfo{}, newGatewayError("GetSlots", resp.HTTPResponse, resp.Body)
 	}
 	return *resp.JSON200, nil
 }
@@ -48,59 +49,106 @@ func (cl *Client) GetPreconfFee(ctx context.Context, slot uint64) (uint64, uint6
 		return 0, 0, fmt.Errorf("Http request for getting preconf fee failed: %w", err)
 	}
 	if resp.JSON200 == nil {
-		return 0, 0, fmt.Errorf("GetPreconfFee return code %v and error `%v`", resp.Status(), string(resp.Body))
+		return 0, 0, newGatewayError("GetPreconfFee", resp.HTTPResponse, resp.Body)
 	}
 	return resp.JSON200.GasFee, resp.JSON200.BlobGasFee, nil
 }
 
-func (cl *Client) signReserveBlockspace(req *types.ReserveBlockSpaceRequest) (string, error) {
-	signature, err := crypto.Sign(req.Digest().Bytes(), cl.key)
+// SetHeadSlotSource sets source of the head slot used by [Reservation.Submit]
+// to check that reserved slot hasn't passed. It's required for submitting
+// through reservations.
+func (cl *Client) SetHeadSlotSource(src HeadSlotSource) {
+	cl.headSlot = src
+}
+
+// Address returns address of the user, on whose behalf client signs requests.
+func (cl *Client) Address() common.Address {
+	return cl.signer.Address()
+}
+
+// sign signs keccak256 of data, passing data itself to [DataSigner].
+func (cl *Client) sign(ctx context.Context, data []byte) ([]byte, error) {
+	if signer, ok := cl.signer.(DataSigner); ok {
+		return signer.SignData(ctx, data)
+	}
+	return cl.signer.SignHash(ctx, crypto.Keccak256Hash(data))
+}
+
+func (cl *Client) signReserveBlockspace(ctx context.Context, req *types.ReserveBlockSpaceRequest) (types.LubanSignature, error) {
+	signature, err := cl.sign(ctx, req.DigestData())
 	if err != nil {
-		return "", err
+		return types.LubanSignature{}, fmt.Errorf("Failed to sign blockspace reservation: %w", err)
 	}
-	addr := crypto.PubkeyToAddress(cl.key.PublicKey)
-	return fmt.Sprintf("%v:0x%s", addr, hex.EncodeToString(signature)), nil
+	return types.NewReserveSignature(cl.signer.Address(), signature), nil
 }
 
 func (cl *Client) ReserveBlockspace(
 	ctx context.Context,
 	req types.ReserveBlockSpaceRequest,
-) (uuid.UUID, error) {
-	sig, err := cl.signReserveBlockspace(&req)
+) (*Reservation, error) {
+	sig, err := cl.signReserveBlockspace(ctx, &req)
 	if err != nil {
-		return uuid.UUID{}, err
+		return nil, err
 	}
 	signature := internal.ReserveBlockspaceParams{
-		XLubanSignature: sig,
+		XLubanSignature: sig.String(),
 	}
 	body := internal.ReserveBlockSpaceRequest(req)
 	resp, err := cl.ClientWithResponses.ReserveBlockspaceWithResponse(ctx, &signature, body)
 	if err != nil {
-		return uuid.UUID{}, err
+		return nil, fmt.Errorf("ReserveBlockspace http request failed: %w", err)
 	}
 	if resp.JSON200 == nil {
-		return uuid.UUID{}, fmt.Errorf("ReserveBlockspace return code %v", resp.Status())
+		return nil, newGatewayError("ReserveBlockspace", resp.HTTPResponse, resp.Body)
 	}
-	return uuid.UUID(*resp.JSON200), nil
+	receipt, err := parseReservation(resp.JSON200)
+	if err != nil {
+		return nil, fmt.Errorf("ReserveBlockspace returned malformed response: %w", err)
+	}
+	return &Reservation{
+		Id:        receipt.RequestId,
+		Request:   req,
+		Signature: sig,
+		Receipt:   receipt,
+		client:    cl,
+	}, nil
 }
 
-func (cl *Client) signSubmitTx(reqId uuid.UUID, tx *types.Transaction) (string, error) {
-	signature, err := crypto.Sign(types.SubmitTxDigest(reqId, tx).Bytes(), cl.key)
+// Gateway returns either bare request id or signed receipt
+func parseReservation(resp *internal.ReserveBlockSpaceResponse) (types.ReserveBlockSpaceResponse, error) {
+	if receipt, err := resp.AsReservationReceipt(); err == nil {
+		sig, err := hexutil.Decode(receipt.Signature)
+		if err != nil {
+			return types.ReserveBlockSpaceResponse{}, fmt.Errorf("invalid reservation signature: %w", err)
+		}
+		return types.ReserveBlockSpaceResponse{RequestId: receipt.RequestId, Signature: sig}, nil
+	}
+	id, err := resp.AsReservationId()
 	if err != nil {
-		return "", fmt.Errorf("Failed to sign preconf tx: %w", err)
+		return types.ReserveBlockSpaceResponse{}, err
 	}
-	return fmt.Sprintf("0x%s", hex.EncodeToString(signature)), nil
+	return types.ReserveBlockSpaceResponse{RequestId: id}, nil
 }
 
-// TODO: Handle slashing and everything
-func (cl *Client) SubmitTransaction(ctx context.Context, reqId uuid.UUID, tx *types.Transaction) error {
-	sig, err := cl.signSubmitTx(reqId, tx)
+func (cl *Client) signSubmitTx(ctx context.Context, reqId uuid.UUID, tx *types.Transaction) (types.LubanSignature, error) {
+	signature, err := cl.sign(ctx, types.SubmitTxDigestData(reqId, tx))
 	if err != nil {
-		return err
+		return types.LubanSignature{}, fmt.Errorf("Failed to sign preconf tx: %w", err)
+	}
+	return types.NewSubmitSignature(signature), nil
+}
+
+// SubmitTransaction submits tx for blockspace reserved under reqId and returns
+// gateway's commitment to include it. Use [types.Commitment.Verify] to check
+// that commitment is signed by the gateway.
+func (cl *Client) SubmitTransaction(ctx context.Context, reqId uuid.UUID, tx *types.Transaction) (types.Commitment, error) {
+	sig, err := cl.signSubmitTx(ctx, reqId, tx)
+	if err != nil {
+		return types.Commitment{}, err
 	}
 
 	params := internal.SubmitTransactionParams{
-		XLubanSignature: sig,
+		XLubanSignature: sig.String(),
 	}
 	req := internal.SubmitTransactionRequest{
 		RequestId:   reqId,
@@ -108,10 +156,15 @@ func (cl *Client) SubmitTransaction(ctx context.Context, reqId uuid.UUID, tx *ty
 	}
 	resp, err := cl.SubmitTransactionWithResponse(ctx, &params, req)
 	if err != nil {
-		return fmt.Errorf("SubmitTransaction http request failed: %w", err)
+		return types.Commitment{}, fmt.Errorf("SubmitTransaction http request failed: %w", err)
 	}
 	if resp.JSON200 == nil {
-		return fmt.Errorf("SubmitTransaction return code %v: %s", resp.Status(), string(resp.Body))
+		return types.Commitment{}, newGatewayError("SubmitTransaction", resp.HTTPResponse, resp.Body)
+	}
+	c := resp.JSON200.Data.Commitment
+	commitment, err := types.ParseCommitment(c.R, c.S, c.V, c.YParity)
+	if err != nil {
+		return types.Commitment{}, fmt.Errorf("SubmitTransaction returned malformed commitment: %w", err)
 	}
-	return err
+	return commitment, nil
 }
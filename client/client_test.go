@@ -4,13 +4,10 @@ import (
 	"context"
 	"crypto/ecdsa"
 	"encoding/hex"
-	"encoding/json"
 	"fmt"
 	"math/big"
-	"net/http"
-	"strconv"
+	"os"
 	"testing"
-	"time"
 
 	u256 "github.com/holiman/uint256"
 
@@ -29,6 +26,7 @@ import (
 	"github.com/ethereum-optimism/optimism/op-service/eth"
 	"github.com/ethereum-optimism/optimism/op-service/txmgr"
 
+	"github.com/risechain/luban-api/beacon"
 	"github.com/risechain/luban-api/escrow"
 	luban "github.com/risechain/luban-api/types"
 )
@@ -39,50 +37,11 @@ type testSetup struct {
 	Key       *ecdsa.PrivateKey
 	Preconfer *Client
 	ChainId   *big.Int
-	beaconUrl string
+	Beacon    beacon.BeaconClient
+	Clock     *beacon.SlotClock
 	ctx       context.Context
 }
 
-func (t *testSetup) getHeadSlot() (uint64, error) {
-	url := fmt.Sprintf("%s/eth/v1/node/syncing", t.beaconUrl)
-
-	// Make the HTTP GET request
-	resp, err := http.Get(url)
-	if err != nil {
-		return 0, fmt.Errorf("failed to make GET request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	// Check for non-200 status codes
-	if resp.StatusCode != http.StatusOK {
-		return 0, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
-	}
-
-	// Define a struct to match the JSON structure
-	var syncingResponse struct {
-		Data struct {
-			HeadSlot     string `json:"head_slot"`
-			SyncDistance string `json:"sync_distance"`
-			IsSyncing    bool   `json:"is_syncing"`
-			IsOptimistic bool   `json:"is_optimistic"`
-			ELOffline    bool   `json:"el_offline"`
-		} `json:"data"`
-	}
-
-	// Decode the JSON response directly into the struct
-	if err := json.NewDecoder(resp.Body).Decode(&syncingResponse); err != nil {
-		return 0, fmt.Errorf("failed to decode JSON: %w", err)
-	}
-
-	// Convert the head_slot to uint64
-	headSlot, err := strconv.ParseUint(syncingResponse.Data.HeadSlot, 10, 64)
-	if err != nil {
-		return 0, fmt.Errorf("invalid head_slot value: %w", err)
-	}
-
-	return headSlot, nil
-}
-
 func (t *testSetup) getBaseFee() *big.Int {
 	bn, err := t.Rpc.BlockNumber(t.ctx)
 	if err != nil {
@@ -145,7 +104,7 @@ func (t *testSetup) Deposit(amount *big.Int) {
 	fmt.Printf("Receipt: %v\n", receipt)
 }
 
-func newSetup(key, escrowAddr, gateway, beacon, rpcUrl string, chainId *big.Int) *testSetup {
+func newSetup(key, escrowAddr, gateway, beaconUrl, rpcUrl string, chainId *big.Int) *testSetup {
 	ecdsa, err := crypto.HexToECDSA(key)
 	if err != nil {
 		panic(err)
@@ -167,7 +126,13 @@ func newSetup(key, escrowAddr, gateway, beacon, rpcUrl string, chainId *big.Int)
 		panic(err)
 	}
 
-	preconfer, err := NewClient(gateway, ecdsa)
+	preconfer, err := NewClient(gateway, NewPrivateKeySigner(ecdsa))
+	if err != nil {
+		panic(err)
+	}
+
+	bn := beacon.NewClient(beaconUrl)
+	clock, err := beacon.NewSlotClockFromBeacon(context.Background(), bn, nil)
 	if err != nil {
 		panic(err)
 	}
@@ -178,7 +143,8 @@ func newSetup(key, escrowAddr, gateway, beacon, rpcUrl string, chainId *big.Int)
 		Key:       ecdsa,
 		Preconfer: preconfer,
 		ChainId:   chainId,
-		beaconUrl: beacon,
+		Beacon:    bn,
+		Clock:     clock,
 		ctx:       context.Background(),
 	}
 }
@@ -195,9 +161,9 @@ func newTestSetup(key string) *testSetup {
 }
 
 func TestSubmitTxDigest(t *testing.T) {
-	key, notSet := os.Lookupenv("TEST_LUBAN_KEY")
-	if notSet {
-		return
+	key, ok := os.LookupEnv("TEST_LUBAN_KEY")
+	if !ok {
+		t.Skip("TEST_LUBAN_KEY is not set")
 	}
 	setup := newTestSetup(key)
 
@@ -231,10 +197,7 @@ func TestSubmitTxDigest(t *testing.T) {
 		panic(err)
 	}
 
-	head, err := setup.getHeadSlot()
-	if err != nil {
-		panic(err)
-	}
+	head := setup.Clock.CurrentSlot()
 
 	var slot uint64
 	for _, s := range slots {
@@ -256,21 +219,26 @@ func TestSubmitTxDigest(t *testing.T) {
 		panic(err)
 	}
 
-	// gas_limit * gas_fee * 0.5
-	gasLimit := u256.NewInt(gas)
-	gasLimit = gasLimit.Mul(gasLimit, u256.NewInt(gasPrice))
-	deposit := gasLimit.Div(gasLimit, u256.NewInt(2))
+	deposit, err := luban.ComputeDeposit(gas, 0, u256.NewInt(gasPrice), u256.NewInt(0))
+	if err != nil {
+		panic(err)
+	}
+	tip, err := luban.ComputeTip(gas, 0, u256.NewInt(gasPrice), u256.NewInt(0))
+	if err != nil {
+		panic(err)
+	}
 
-	id, err := setup.Preconfer.ReserveBlockspace(setup.ctx, luban.ReserveBlockSpaceRequest{
+	reservation, err := setup.Preconfer.ReserveBlockspace(setup.ctx, luban.ReserveBlockSpaceRequest{
 		Deposit:    hexutil.U256(*deposit),
 		GasLimit:   gas,
 		TargetSlot: slot,
-		Tip:        hexutil.U256(*deposit),
+		Tip:        hexutil.U256(*tip),
 	})
 	if err != nil {
 		panic(err)
 	}
 
+	id := reservation.Id
 	fmt.Printf("Preconf id: %v\n", id)
 
 	nonce, err := setup.Rpc.NonceAt(setup.ctx, addr, nil)
@@ -288,23 +256,20 @@ func TestSubmitTxDigest(t *testing.T) {
 	}
 	signer := types.LatestSignerForChainID(setup.ChainId)
 	tx := types.MustSignNewTx(setup.Key, signer, txMessage)
-	err = setup.Preconfer.SubmitTransaction(setup.ctx, id, tx)
+	commitment, err := setup.Preconfer.SubmitTransaction(setup.ctx, id, tx)
 	if err != nil {
 		panic(err)
 	}
 	fmt.Printf("Submitted tx with hash: %v\n", tx.Hash())
-
-	head, err = setup.getHeadSlot()
+	gateway, err := commitment.Signer(id, tx)
 	if err != nil {
 		panic(err)
 	}
-	fmt.Printf("Waiting for slot %d (head is %d)\n", slot, head)
-	for head < slot+1 {
-		time.Sleep(12 * time.Second)
-		head, err = setup.getHeadSlot()
-		if err != nil {
-			panic(err)
-		}
+	fmt.Printf("Commitment signed by: %v\n", gateway)
+
+	fmt.Printf("Waiting for slot %d (current slot is %d)\n", slot, setup.Clock.CurrentSlot())
+	if err := setup.Clock.WaitForSlot(setup.ctx, slot+1); err != nil {
+		panic(err)
 	}
 
 	otherTx, pending, err := setup.Rpc.TransactionByHash(setup.ctx, tx.Hash())
@@ -322,7 +287,11 @@ func TestSubmitTxDigest(t *testing.T) {
 }
 
 func TestSubmitBlob(t *testing.T) {
-	setup := newTestSetup()
+	key, ok := os.LookupEnv("TEST_LUBAN_KEY")
+	if !ok {
+		t.Skip("TEST_LUBAN_KEY is not set")
+	}
+	setup := newTestSetup(key)
 
 	balance := setup.Balance()
 	fmt.Printf("Our balance is %v\n", balance)
@@ -349,10 +318,7 @@ func TestSubmitBlob(t *testing.T) {
 		panic(err)
 	}
 
-	head, err := setup.getHeadSlot()
-	if err != nil {
-		panic(err)
-	}
+	head := setup.Clock.CurrentSlot()
 
 	var slot uint64
 	for _, s := range slots {
@@ -384,15 +350,18 @@ func TestSubmitBlob(t *testing.T) {
 	}
 	fmt.Printf("Price is: %v %v\n", gasPrice, blobPrice)
 
-	// { gas_limit * gas_fee + blob_count * blob_gas_fee } * 0.5
-	gas := u256.NewInt(gasEstimate)
-	gas = gas.Mul(gas, u256.NewInt(gasPrice))
-	blob := u256.NewInt(blobPrice)
-	deposit := gas.Add(gas, blob).Div(gas, u256.NewInt(2))
+	deposit, err := luban.ComputeDeposit(gasEstimate, 1, u256.NewInt(gasPrice), u256.NewInt(blobPrice))
+	if err != nil {
+		panic(err)
+	}
+	tip, err := luban.ComputeTip(gasEstimate, 1, u256.NewInt(gasPrice), u256.NewInt(blobPrice))
+	if err != nil {
+		panic(err)
+	}
 
-	id, err := setup.Preconfer.ReserveBlockspace(setup.ctx, luban.ReserveBlockSpaceRequest{
+	reservation, err := setup.Preconfer.ReserveBlockspace(setup.ctx, luban.ReserveBlockSpaceRequest{
 		Deposit:    hexutil.U256(*deposit),
-		Tip:        hexutil.U256(*deposit),
+		Tip:        hexutil.U256(*tip),
 		BlobCount:  1,
 		GasLimit:   gasEstimate,
 		TargetSlot: slot,
@@ -417,23 +386,20 @@ func TestSubmitBlob(t *testing.T) {
 	}
 	signer := types.LatestSignerForChainID(setup.ChainId)
 	tx := types.MustSignNewTx(setup.Key, signer, txMessage)
-	err = setup.Preconfer.SubmitTransaction(setup.ctx, id, tx)
+	commitment, err := reservation.Submit(setup.ctx, tx)
 	if err != nil {
 		panic(err)
 	}
 	fmt.Printf("Submitted tx with hash: %v\n", tx.Hash())
-
-	head, err = setup.getHeadSlot()
+	gateway, err := commitment.Signer(reservation.Id, tx)
 	if err != nil {
 		panic(err)
 	}
-	fmt.Printf("Waiting for slot %d (head is %d)\n", slot, head)
-	for head < slot+1 {
-		head, err = setup.getHeadSlot()
-		if err != nil {
-			panic(err)
-		}
-		time.Sleep(time.Second)
+	fmt.Printf("Commitment signed by: %v\n", gateway)
+
+	fmt.Printf("Waiting for slot %d (current slot is %d)\n", slot, setup.Clock.CurrentSlot())
+	if err := setup.Clock.WaitForSlot(setup.ctx, slot+1); err != nil {
+		panic(err)
 	}
 
 	otherTx, pending, err := setup.Rpc.TransactionByHash(setup.ctx, tx.Hash())
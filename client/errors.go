@@ -0,0 +1,112 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+var (
+	// ErrBlockspaceUnavailable is returned when requested slot doesn't have
+	// enough gas, blobs or constraints left, usually because someone else
+	// reserved it first.
+	ErrBlockspaceUnavailable = errors.New("requested blockspace not available")
+	// ErrAlreadySubmitted is returned when transaction for the reservation was
+	// already submitted.
+	ErrAlreadySubmitted = errors.New("transaction already submitted")
+	// ErrInvalidSignature is returned when gateway rejects x-luban-signature.
+	ErrInvalidSignature = errors.New("invalid signature")
+	// ErrInvalidRequest is returned for all other requests rejected by gateway.
+	ErrInvalidRequest = errors.New("invalid request")
+	// ErrGatewayInternal is returned when gateway fails to process request on
+	// its side.
+	ErrGatewayInternal = errors.New("gateway internal error")
+	// ErrGatewayUnavailable is returned when gateway, or a proxy in front of
+	// it, can't take the request right now, e.g. on rate limiting or timeout.
+	// Request may be retried.
+	ErrGatewayUnavailable = errors.New("gateway temporarily unavailable")
+)
+
+// statusKinds classifies statuses, which mean the same regardless of the
+// message.
+var statusKinds = map[int]error{
+	http.StatusUnauthorized:    ErrInvalidSignature,
+	http.StatusForbidden:       ErrInvalidSignature,
+	http.StatusNotFound:        ErrGatewayUnavailable,
+	http.StatusRequestTimeout:  ErrGatewayUnavailable,
+	http.StatusTooEarly:        ErrGatewayUnavailable,
+	http.StatusTooManyRequests: ErrGatewayUnavailable,
+}
+
+// messageKinds classifies 400 responses. The spec defines no error codes
+// besides http status, so messages documented by the spec are matched. The
+// gateway may append details after a colon.
+var messageKinds = map[string]error{
+	"requested blockspace not available for slot":           ErrBlockspaceUnavailable,
+	"transaction for this request id was already submitted": ErrAlreadySubmitted,
+	"invalid signature": ErrInvalidSignature,
+}
+
+// GatewayError is the error returned by gateway as `{code, message}` body.
+//
+// It matches with [errors.Is] one of the sentinel errors of this package,
+// depending on the status code and the message.
+type GatewayError struct {
+	// Op is the gateway operation failed, e.g. "ReserveBlockspace"
+	Op         string
+	StatusCode int
+	// Code is either specific error code or http status code
+	Code    int
+	Message string
+}
+
+func newGatewayError(op string, resp *http.Response, body []byte) *GatewayError {
+	gerr := &GatewayError{Op: op}
+	if resp != nil {
+		gerr.StatusCode = resp.StatusCode
+	}
+
+	var parsed struct {
+		Code    *float64 `json:"code"`
+		Message *string  `json:"message"`
+	}
+	if err := json.Unmarshal(body, &parsed); err == nil && parsed.Message != nil {
+		gerr.Message = *parsed.Message
+		if parsed.Code != nil {
+			gerr.Code = int(*parsed.Code)
+		}
+	} else {
+		gerr.Message = strings.TrimSpace(string(body))
+	}
+	if gerr.Code == 0 {
+		gerr.Code = gerr.StatusCode
+	}
+	return gerr
+}
+
+func (e *GatewayError) Error() string {
+	return fmt.Sprintf("%s failed with status %d (code %d): %s", e.Op, e.StatusCode, e.Code, e.Message)
+}
+
+// Kind returns sentinel error describing e. It's decided by the status code
+// first and by the documented message of 400 responses then.
+func (e *GatewayError) Kind() error {
+	if e.StatusCode >= 500 {
+		return ErrGatewayInternal
+	}
+	if kind, ok := statusKinds[e.StatusCode]; ok {
+		return kind
+	}
+
+	msg, _, _ := strings.Cut(e.Message, ":")
+	if kind, ok := messageKinds[strings.ToLower(strings.TrimSpace(msg))]; ok {
+		return kind
+	}
+	return ErrInvalidRequest
+}
+
+func (e *GatewayError) Is(target error) bool {
+	return e.Kind() == target
+}
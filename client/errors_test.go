@@ -0,0 +1,60 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/risechain/luban-api/types"
+)
+
+func TestGatewayErrors(t *testing.T) {
+	tests := []struct {
+		status int
+		body   string
+		want   error
+	}{
+		{400, `{"code":400,"message":"requested blockspace not available for slot"}`, ErrBlockspaceUnavailable},
+		{400, `{"code":400,"message":"Transaction for this request id was already submitted"}`, ErrAlreadySubmitted},
+		{400, `{"code":400,"message":"Invalid signature: recovered wrong address"}`, ErrInvalidSignature},
+		{400, `{"code":42,"message":"gas limit too high"}`, ErrInvalidRequest},
+		{400, `{"code":400,"message":"deposit is not available in escrow"}`, ErrInvalidRequest},
+		{401, `unauthorized`, ErrInvalidSignature},
+		{404, `404 page not found`, ErrGatewayUnavailable},
+		{408, `request timeout`, ErrGatewayUnavailable},
+		{429, `{"code":429,"message":"requested blockspace not available for slot"}`, ErrGatewayUnavailable},
+		{500, `{"code":500,"message":"internal server error"}`, ErrGatewayInternal},
+		{502, `bad gateway`, ErrGatewayInternal},
+	}
+
+	key, _ := crypto.GenerateKey()
+	for _, test := range tests {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(test.status)
+			_, _ = w.Write([]byte(test.body))
+		}))
+
+		cl, err := NewClient(srv.URL, NewPrivateKeySigner(key))
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = cl.ReserveBlockspace(context.Background(), types.ReserveBlockSpaceRequest{})
+		srv.Close()
+
+		var gerr *GatewayError
+		if !errors.As(err, &gerr) {
+			t.Fatalf("Expected GatewayError for %q, have %v", test.body, err)
+		}
+		if gerr.StatusCode != test.status {
+			t.Errorf("Wrong status code for %q. Have %d, want %d", test.body, gerr.StatusCode, test.status)
+		}
+		if !errors.Is(err, test.want) {
+			t.Errorf("Wrong error kind for %q. Have %v, want %v", test.body, gerr.Kind(), test.want)
+		}
+	}
+}
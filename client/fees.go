@@ -0,0 +1,161 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultFeeTTL is how long fee quotes are cached by default, which is a
+// duration of one slot.
+const DefaultFeeTTL = 12 * time.Second
+
+// FeeQuote is a preconf fee for a slot, denominated in wei
+type FeeQuote struct {
+	GasFee     uint64
+	BlobGasFee uint64
+}
+
+// FeeSource is anything, which quotes preconf fees, e.g. [Client] or [MultiClient]
+type FeeSource interface {
+	GetPreconfFee(ctx context.Context, slot uint64) (uint64, uint64, error)
+}
+
+// SlotTimer reports when slot starts
+type SlotTimer interface {
+	SlotStart(slot uint64) time.Time
+}
+
+// GetPreconfFees fetches quotes for slots from src concurrently. Quotes,
+// which were fetched successfully, are returned even if others failed.
+func GetPreconfFees(ctx context.Context, src FeeSource, slots []uint64) (map[uint64]FeeQuote, error) {
+	var (
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+		errs   []error
+		quotes = make(map[uint64]FeeQuote, len(slots))
+	)
+	for _, slot := range slots {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			gasFee, blobFee, err := src.GetPreconfFee(ctx, slot)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs = append(errs, fmt.Errorf("slot %d: %w", slot, err))
+				return
+			}
+			quotes[slot] = FeeQuote{GasFee: gasFee, BlobGasFee: blobFee}
+		}()
+	}
+	wg.Wait()
+	return quotes, errors.Join(errs...)
+}
+
+// GetPreconfFees fetches quotes for several slots concurrently. See [GetPreconfFees].
+func (cl *Client) GetPreconfFees(ctx context.Context, slots []uint64) (map[uint64]FeeQuote, error) {
+	return GetPreconfFees(ctx, cl, slots)
+}
+
+// GetPreconfFees fetches the cheapest quotes for several slots concurrently.
+// See [GetPreconfFees].
+func (m *MultiClient) GetPreconfFees(ctx context.Context, slots []uint64) (map[uint64]FeeQuote, error) {
+	return GetPreconfFees(ctx, m, slots)
+}
+
+type cachedQuote struct {
+	FeeQuote
+	expires time.Time
+}
+
+// FeeCache caches fee quotes per slot. Quote expires after TTL or once the
+// slot starts, whichever happens first.
+type FeeCache struct {
+	src   FeeSource
+	ttl   time.Duration
+	timer SlotTimer
+	now   func() time.Time
+
+	mu     sync.Mutex
+	quotes map[uint64]cachedQuote
+}
+
+// NewFeeCache caches quotes from src for ttl. If timer is not nil, quotes
+// also expire at the start of their slot.
+func NewFeeCache(src FeeSource, ttl time.Duration, timer SlotTimer) *FeeCache {
+	return &FeeCache{
+		src:    src,
+		ttl:    ttl,
+		timer:  timer,
+		now:    time.Now,
+		quotes: make(map[uint64]cachedQuote),
+	}
+}
+
+func (c *FeeCache) cached(slot uint64) (FeeQuote, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	q, ok := c.quotes[slot]
+	if !ok {
+		return FeeQuote{}, false
+	}
+	if !c.now().Before(q.expires) {
+		delete(c.quotes, slot)
+		return FeeQuote{}, false
+	}
+	return q.FeeQuote, true
+}
+
+func (c *FeeCache) store(slot uint64, quote FeeQuote) {
+	expires := c.now().Add(c.ttl)
+	if c.timer != nil {
+		if start := c.timer.SlotStart(slot); start.Before(expires) {
+			expires = start
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.quotes[slot] = cachedQuote{FeeQuote: quote, expires: expires}
+}
+
+// GetPreconfFee returns cached quote for the slot or fetches a new one.
+func (c *FeeCache) GetPreconfFee(ctx context.Context, slot uint64) (uint64, uint64, error) {
+	if q, ok := c.cached(slot); ok {
+		return q.GasFee, q.BlobGasFee, nil
+	}
+	gasFee, blobFee, err := c.src.GetPreconfFee(ctx, slot)
+	if err != nil {
+		return 0, 0, err
+	}
+	c.store(slot, FeeQuote{GasFee: gasFee, BlobGasFee: blobFee})
+	return gasFee, blobFee, nil
+}
+
+// GetPreconfFees returns quotes for several slots, fetching missing ones
+// concurrently. See [GetPreconfFees].
+func (c *FeeCache) GetPreconfFees(ctx context.Context, slots []uint64) (map[uint64]FeeQuote, error) {
+	return GetPreconfFees(ctx, c, slots)
+}
+
+// Invalidate drops cached quote for the slot, e.g. after reservation failed.
+func (c *FeeCache) Invalidate(slot uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.quotes, slot)
+}
+
+// Prune drops quotes of slots up to and including head.
+func (c *FeeCache) Prune(head uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for slot := range c.quotes {
+		if slot <= head {
+			delete(c.quotes, slot)
+		}
+	}
+}
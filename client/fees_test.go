@@ -0,0 +1,77 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/risechain/luban-api/lubantest"
+)
+
+type fixedTimer time.Time
+
+func (t fixedTimer) SlotStart(slot uint64) time.Time {
+	return time.Time(t).Add(time.Duration(slot) * time.Second)
+}
+
+func TestFeeCache(t *testing.T) {
+	ctx := context.Background()
+	gateway, preconfer, _ := newMockSetup(t)
+	gateway.SetDefaultFee(lubantest.Fee{GasFee: 5, BlobGasFee: 1})
+
+	now := time.Unix(1_700_000_000, 0)
+	cache := NewFeeCache(preconfer, DefaultFeeTTL, fixedTimer(now))
+	cache.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		if gasFee, _, err := cache.GetPreconfFee(ctx, 20); err != nil || gasFee != 5 {
+			t.Fatalf("Wrong quote. Have (%d, %v), want 5", gasFee, err)
+		}
+	}
+	if have := gateway.Requests(lubantest.EndpointFee); have != 1 {
+		t.Fatalf("Quote wasn't cached. Have %d requests, want 1", have)
+	}
+
+	// Quote for slot 2 expires at slot start, before TTL
+	if _, _, err := cache.GetPreconfFee(ctx, 2); err != nil {
+		t.Fatal(err)
+	}
+	now = now.Add(2 * time.Second)
+	if _, _, err := cache.GetPreconfFee(ctx, 2); err != nil {
+		t.Fatal(err)
+	}
+	if have := gateway.Requests(lubantest.EndpointFee); have != 3 {
+		t.Fatalf("Quote outlived its slot. Have %d requests, want 3", have)
+	}
+
+	now = now.Add(DefaultFeeTTL)
+	gateway.SetFee(20, lubantest.Fee{GasFee: 7, BlobGasFee: 1})
+	if gasFee, _, _ := cache.GetPreconfFee(ctx, 20); gasFee != 7 {
+		t.Fatalf("Quote outlived TTL. Have %d, want 7", gasFee)
+	}
+}
+
+func TestGetPreconfFees(t *testing.T) {
+	ctx := context.Background()
+	gateway, preconfer, _ := newMockSetup(t)
+	gateway.SetDefaultFee(lubantest.Fee{GasFee: 5, BlobGasFee: 1})
+	gateway.SetFee(11, lubantest.Fee{GasFee: 3, BlobGasFee: 2})
+
+	quotes, err := preconfer.GetPreconfFees(ctx, []uint64{10, 11, 12})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(quotes) != 3 || quotes[11] != (FeeQuote{GasFee: 3, BlobGasFee: 2}) || quotes[12].GasFee != 5 {
+		t.Fatalf("Wrong quotes: %+v", quotes)
+	}
+
+	gateway.FailNext(lubantest.EndpointFee, http.StatusInternalServerError, lubantest.MsgInternal)
+	quotes, err = preconfer.GetPreconfFees(ctx, []uint64{10, 11, 12})
+	if err == nil {
+		t.Fatal("Failed quote wasn't reported")
+	}
+	if len(quotes) != 2 {
+		t.Fatalf("Successful quotes weren't returned: %+v", quotes)
+	}
+}
@@ -0,0 +1,247 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/risechain/luban-api/lubantest"
+	luban "github.com/risechain/luban-api/types"
+)
+
+func newMockSetup(t *testing.T) (*lubantest.Gateway, *Client, func(gas uint64) *types.Transaction) {
+	gatewayKey, _ := crypto.GenerateKey()
+	gateway := lubantest.NewGateway(gatewayKey)
+	t.Cleanup(gateway.Close)
+
+	key, _ := crypto.GenerateKey()
+	preconfer, err := NewClient(gateway.URL, NewPrivateKeySigner(key))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chainId := big.NewInt(7028081469)
+	addr := crypto.PubkeyToAddress(key.PublicKey)
+	newTx := func(gas uint64) *types.Transaction {
+		return types.MustSignNewTx(key, types.LatestSignerForChainID(chainId), &types.DynamicFeeTx{
+			ChainID:   chainId,
+			To:        &addr,
+			GasFeeCap: big.NewInt(1),
+			Gas:       gas,
+		})
+	}
+	return gateway, preconfer, newTx
+}
+
+func TestMockSubmitTransaction(t *testing.T) {
+	ctx := context.Background()
+	gateway, preconfer, newTx := newMockSetup(t)
+	gateway.AddSlot(luban.SlotInfo{Slot: 10, GasAvailable: 30_000_000, BlobsAvailable: 6})
+	gateway.SetFee(10, lubantest.Fee{GasFee: 7, BlobGasFee: 3})
+
+	slots, err := preconfer.GetSlots(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(slots) != 1 || slots[0].Slot != 10 {
+		t.Fatalf("Wrong slots: %+v", slots)
+	}
+
+	gasFee, blobFee, err := preconfer.GetPreconfFee(ctx, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gasFee != 7 || blobFee != 3 {
+		t.Fatalf("Wrong fee. Have (%d, %d), want (7, 3)", gasFee, blobFee)
+	}
+
+	tx := newTx(21000)
+	reservation, err := preconfer.ReserveBlockspace(ctx, luban.ReserveBlockSpaceRequest{
+		GasLimit:   tx.Gas(),
+		TargetSlot: 10,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := reservation.Id
+	if slot, _ := gateway.Slot(10); slot.GasAvailable != 30_000_000-21000 {
+		t.Fatalf("Reservation didn't consume gas. Available %d", slot.GasAvailable)
+	}
+
+	commitment, err := preconfer.SubmitTransaction(ctx, id, tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := commitment.Verify(id, tx, gateway.Address()); err != nil {
+		t.Fatalf("Commitment is not signed by gateway: %v", err)
+	}
+	if reservation, _ := gateway.Reservation(id); reservation.Tx.Hash() != tx.Hash() {
+		t.Fatalf("Gateway has wrong tx for reservation")
+	}
+
+	_, err = preconfer.SubmitTransaction(ctx, id, tx)
+	if !errors.Is(err, ErrAlreadySubmitted) {
+		t.Fatalf("Expected %v on resubmission, have %v", ErrAlreadySubmitted, err)
+	}
+}
+
+func TestMockSlotRace(t *testing.T) {
+	ctx := context.Background()
+	gateway, preconfer, _ := newMockSetup(t)
+	gateway.AddSlot(luban.SlotInfo{Slot: 10, GasAvailable: 30_000_000, BlobsAvailable: 6})
+	gateway.RaceNextReservation()
+
+	req := luban.ReserveBlockSpaceRequest{GasLimit: 21000, TargetSlot: 10}
+	if _, err := preconfer.ReserveBlockspace(ctx, req); !errors.Is(err, ErrBlockspaceUnavailable) {
+		t.Fatalf("Expected %v, have %v", ErrBlockspaceUnavailable, err)
+	}
+}
+
+func TestMockInjectedFailures(t *testing.T) {
+	ctx := context.Background()
+	gateway, preconfer, newTx := newMockSetup(t)
+	gateway.AddSlot(luban.SlotInfo{Slot: 10, GasAvailable: 30_000_000, BlobsAvailable: 6})
+	gateway.FailNext(lubantest.EndpointSubmit, http.StatusInternalServerError, lubantest.MsgInternal)
+	preconfer.SetHeadSlotSource(fixedHead(8))
+
+	tx := newTx(21000)
+	reservation, err := preconfer.ReserveBlockspace(ctx, luban.ReserveBlockSpaceRequest{
+		GasLimit:   tx.Gas(),
+		TargetSlot: 10,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := reservation.Submit(ctx, tx); !errors.Is(err, ErrGatewayInternal) {
+		t.Fatalf("Expected %v, have %v", ErrGatewayInternal, err)
+	}
+	if _, err := reservation.Submit(ctx, tx); err != nil {
+		t.Fatalf("Failure was injected more than once: %v", err)
+	}
+}
+
+func TestMockSignatureMismatch(t *testing.T) {
+	ctx := context.Background()
+	gateway, preconfer, newTx := newMockSetup(t)
+	gateway.AddSlot(luban.SlotInfo{Slot: 10, GasAvailable: 30_000_000, BlobsAvailable: 6})
+
+	tx := newTx(21000)
+	reservation, err := preconfer.ReserveBlockspace(ctx, luban.ReserveBlockSpaceRequest{GasLimit: tx.Gas(), TargetSlot: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	otherKey, _ := crypto.GenerateKey()
+	other, err := NewClient(gateway.URL, NewPrivateKeySigner(otherKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := other.SubmitTransaction(ctx, reservation.Id, tx); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("Expected %v, have %v", ErrInvalidSignature, err)
+	}
+}
+
+func TestMockLatency(t *testing.T) {
+	gateway, preconfer, _ := newMockSetup(t)
+	gateway.SetLatency(lubantest.EndpointSlots, time.Second)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := preconfer.GetSlots(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected %v, have %v", context.DeadlineExceeded, err)
+	}
+}
+
+type fixedHead uint64
+
+func (h fixedHead) HeadSlot(ctx context.Context) (uint64, error) {
+	return uint64(h), nil
+}
+
+func TestReservationValidate(t *testing.T) {
+	ctx := context.Background()
+	gateway, preconfer, newTx := newMockSetup(t)
+	gateway.AddSlot(luban.SlotInfo{Slot: 10, GasAvailable: 30_000_000, BlobsAvailable: 6})
+
+	reservation, err := preconfer.ReserveBlockspace(ctx, luban.ReserveBlockSpaceRequest{GasLimit: 21000, TargetSlot: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := reservation.VerifyReceipt(gateway.Address()); err != nil {
+		t.Fatalf("Reservation receipt is not signed by gateway: %v", err)
+	}
+	// Slot can't be checked without head slot source
+	if _, err := reservation.Submit(ctx, newTx(21000)); !errors.Is(err, ErrNoHeadSource) {
+		t.Fatalf("Expected %v, have %v", ErrNoHeadSource, err)
+	}
+	preconfer.SetHeadSlotSource(fixedHead(8))
+	if reservation.TargetSlot() != 10 {
+		t.Fatalf("Wrong target slot. Have %d, want 10", reservation.TargetSlot())
+	}
+	if addr := reservation.Signature.Address; addr == nil || *addr != preconfer.Address() {
+		t.Fatal("Reservation signature doesn't embed our address")
+	}
+
+	if _, err := reservation.Submit(ctx, newTx(21001)); !errors.Is(err, ErrGasLimitExceeded) {
+		t.Fatalf("Expected %v, have %v", ErrGasLimitExceeded, err)
+	}
+	if _, err := reservation.Submit(ctx, newTx(21000)); err != nil {
+		t.Fatal(err)
+	}
+
+	reservation, err = preconfer.ReserveBlockspace(ctx, luban.ReserveBlockSpaceRequest{GasLimit: 21000, TargetSlot: 10, BlobCount: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := reservation.Submit(ctx, newTx(21000)); !errors.Is(err, ErrBlobCountMismatch) {
+		t.Fatalf("Expected %v, have %v", ErrBlobCountMismatch, err)
+	}
+
+	preconfer.SetHeadSlotSource(fixedHead(10))
+	reservation, err = preconfer.ReserveBlockspace(ctx, luban.ReserveBlockSpaceRequest{GasLimit: 21000, TargetSlot: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := reservation.Submit(ctx, newTx(21000)); !errors.Is(err, ErrSlotPassed) {
+		t.Fatalf("Expected %v, have %v", ErrSlotPassed, err)
+	}
+	if have := gateway.Requests(lubantest.EndpointSubmit); have != 1 {
+		t.Fatalf("Invalid transactions reached the gateway. Have %d submissions, want 1", have)
+	}
+
+	// Reservations not made by Client can be validated, but not submitted
+	detached := &Reservation{Request: luban.ReserveBlockSpaceRequest{GasLimit: 21000, TargetSlot: 10}}
+	if err := detached.Validate(ctx, newTx(21000), nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := detached.Validate(ctx, newTx(21000), fixedHead(10)); !errors.Is(err, ErrSlotPassed) {
+		t.Fatalf("Expected %v, have %v", ErrSlotPassed, err)
+	}
+	if _, err := detached.Submit(ctx, newTx(21000)); !errors.Is(err, ErrNoClient) {
+		t.Fatalf("Expected %v, have %v", ErrNoClient, err)
+	}
+}
+
+func TestUnsignedReservation(t *testing.T) {
+	ctx := context.Background()
+	gateway, preconfer, _ := newMockSetup(t)
+	gateway.AddSlot(luban.SlotInfo{Slot: 10, GasAvailable: 30_000_000, BlobsAvailable: 6})
+	gateway.SetUnsignedReservations(true)
+
+	reservation, err := preconfer.ReserveBlockspace(ctx, luban.ReserveBlockSpaceRequest{GasLimit: 21000, TargetSlot: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := gateway.Reservation(reservation.Id); !ok {
+		t.Fatal("Wrong reservation id parsed from bare id response")
+	}
+	if err := reservation.VerifyReceipt(gateway.Address()); !errors.Is(err, luban.ErrUnsignedReservation) {
+		t.Fatalf("Expected %v, have %v", luban.ErrUnsignedReservation, err)
+	}
+}
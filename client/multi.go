@@ -0,0 +1,265 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/risechain/luban-api/types"
+)
+
+var (
+	ErrUnknownReservation = errors.New("reservation wasn't made through this client")
+	// ErrQuoteExceeded is returned, when reservation could only fail over to
+	// gateways quoting more than the request was priced with. Request should
+	// be priced again with a fresh quote.
+	ErrQuoteExceeded = errors.New("gateway quote exceeds the priced quote")
+)
+
+type quote struct {
+	client  *Client
+	gasFee  uint64
+	blobFee uint64
+}
+
+// pin is the gateway, which issued a reservation
+type pin struct {
+	client *Client
+	slot   uint64
+}
+
+// MultiClient talks to several gateways at once. It merges their slots,
+// reserves blockspace on the gateway with the cheapest quote for the slot,
+// failing over to other gateways on errors, and submits transactions to the
+// gateway, which issued the reservation. Reservations are forgotten once
+// their slot is no longer offered by any gateway.
+type MultiClient struct {
+	clients []*Client
+
+	mu sync.Mutex
+	// gateways, which offered slot in the last GetSlots
+	offers map[uint64][]*Client
+	// cheapest quote for slot from the last GetPreconfFee
+	quotes map[uint64]quote
+	pinned map[uuid.UUID]pin
+}
+
+func NewMultiClient(clients ...*Client) (*MultiClient, error) {
+	if len(clients) == 0 {
+		return nil, errors.New("MultiClient requires at least one client")
+	}
+	return &MultiClient{
+		clients: clients,
+		offers:  make(map[uint64][]*Client),
+		quotes:  make(map[uint64]quote),
+		pinned:  make(map[uuid.UUID]pin),
+	}, nil
+}
+
+// forEach calls fn for all clients concurrently and returns errors of each call
+func forEach(clients []*Client, fn func(i int, cl *Client) error) []error {
+	errs := make([]error, len(clients))
+	var wg sync.WaitGroup
+	for i, cl := range clients {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = fn(i, cl)
+		}()
+	}
+	wg.Wait()
+	return errs
+}
+
+// GetSlots returns slots offered by all gateways. If several gateways offer
+// the same slot, one with the most gas available is returned. It fails only if
+// all gateways fail.
+func (m *MultiClient) GetSlots(ctx context.Context) ([]types.SlotInfo, error) {
+	results := make([][]types.SlotInfo, len(m.clients))
+	errs := forEach(m.clients, func(i int, cl *Client) error {
+		slots, err := cl.GetSlots(ctx)
+		results[i] = slots
+		return err
+	})
+
+	merged := make(map[uint64]types.SlotInfo)
+	offers := make(map[uint64][]*Client)
+	ok := false
+	for i, slots := range results {
+		if errs[i] != nil {
+			continue
+		}
+		ok = true
+		for _, s := range slots {
+			offers[s.Slot] = append(offers[s.Slot], m.clients[i])
+			if prev, found := merged[s.Slot]; !found || s.GasAvailable > prev.GasAvailable {
+				merged[s.Slot] = s
+			}
+		}
+	}
+	if !ok {
+		return []types.SlotInfo{}, fmt.Errorf("All gateways failed to get slots: %w", errors.Join(errs...))
+	}
+
+	slots := make([]types.SlotInfo, 0, len(merged))
+	for _, s := range merged {
+		slots = append(slots, s)
+	}
+	sort.Slice(slots, func(i, j int) bool { return slots[i].Slot < slots[j].Slot })
+
+	m.mu.Lock()
+	m.offers = offers
+	for slot := range m.quotes {
+		if _, ok := offers[slot]; !ok {
+			delete(m.quotes, slot)
+		}
+	}
+	// Gateways offer slots from the next one, so earlier reservations are
+	// either submitted or abandoned
+	if len(slots) > 0 {
+		m.prune(slots[0].Slot)
+	}
+	m.mu.Unlock()
+	return slots, nil
+}
+
+// prune forgets reservations for slots before the slot. Caller must hold mu.
+func (m *MultiClient) prune(slot uint64) {
+	for id, p := range m.pinned {
+		if p.slot < slot {
+			delete(m.pinned, id)
+		}
+	}
+}
+
+// candidates returns gateways, which offered the slot, or all gateways, if
+// slot wasn't seen
+func (m *MultiClient) candidates(slot uint64) []*Client {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if offers, ok := m.offers[slot]; ok {
+		return offers
+	}
+	return m.clients
+}
+
+// GetPreconfFee returns the cheapest quote for the slot among gateways, which
+// offer it. Gas fee is compared first, blob fee breaks ties.
+func (m *MultiClient) GetPreconfFee(ctx context.Context, slot uint64) (uint64, uint64, error) {
+	clients := m.candidates(slot)
+	quotes := make([]*quote, len(clients))
+	errs := forEach(clients, func(i int, cl *Client) error {
+		gasFee, blobFee, err := cl.GetPreconfFee(ctx, slot)
+		if err == nil {
+			quotes[i] = &quote{client: cl, gasFee: gasFee, blobFee: blobFee}
+		}
+		return err
+	})
+
+	var best *quote
+	for _, q := range quotes {
+		if q == nil {
+			continue
+		}
+		if best == nil || q.gasFee < best.gasFee || (q.gasFee == best.gasFee && q.blobFee < best.blobFee) {
+			best = q
+		}
+	}
+	if best == nil {
+		return 0, 0, fmt.Errorf("All gateways failed to quote slot %d: %w", slot, errors.Join(errs...))
+	}
+
+	m.mu.Lock()
+	m.quotes[slot] = *best
+	m.mu.Unlock()
+	return best.gasFee, best.blobFee, nil
+}
+
+// covers reports whether reservation req, priced with quote q, is priced
+// enough for the gateway quoting gasFee and blobFee.
+func (q quote) covers(req *types.ReserveBlockSpaceRequest, gasFee, blobFee uint64) bool {
+	return gasFee <= q.gasFee && (req.BlobCount == 0 || blobFee <= q.blobFee)
+}
+
+// ReserveBlockspace reserves blockspace on the gateway with the cheapest
+// quote for the target slot and fails over to other gateways offering the
+// slot. Deposit and tip are assumed to be priced with the quote returned by
+// [MultiClient.GetPreconfFee] and are never changed, so it fails over only to
+// gateways quoting no more than that. Pricier gateways are skipped with
+// [ErrQuoteExceeded]. Reservation is pinned to the gateway, which issued it.
+func (m *MultiClient) ReserveBlockspace(ctx context.Context, req types.ReserveBlockSpaceRequest) (*Reservation, error) {
+	clients := m.candidates(req.TargetSlot)
+
+	m.mu.Lock()
+	best, quoted := m.quotes[req.TargetSlot]
+	m.mu.Unlock()
+
+	ordered := make([]*Client, 0, len(clients))
+	if quoted {
+		ordered = append(ordered, best.client)
+	}
+	for _, cl := range clients {
+		if !quoted || cl != best.client {
+			ordered = append(ordered, cl)
+		}
+	}
+
+	var errs []error
+	for _, cl := range ordered {
+		if quoted && cl != best.client {
+			gasFee, blobFee, err := cl.GetPreconfFee(ctx, req.TargetSlot)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			if !best.covers(&req, gasFee, blobFee) {
+				errs = append(errs, fmt.Errorf("%w: gas fee %d, blob fee %d", ErrQuoteExceeded, gasFee, blobFee))
+				continue
+			}
+		}
+
+		reservation, err := cl.ReserveBlockspace(ctx, req)
+		if err != nil {
+			errs = append(errs, err)
+			if ctx.Err() != nil {
+				break
+			}
+			continue
+		}
+
+		m.mu.Lock()
+		m.pinned[reservation.Id] = pin{client: cl, slot: req.TargetSlot}
+		m.mu.Unlock()
+		return reservation, nil
+	}
+	return nil, errors.Join(errs...)
+}
+
+// SubmitTransaction submits tx to the gateway, which issued reservation reqId.
+func (m *MultiClient) SubmitTransaction(ctx context.Context, reqId uuid.UUID, tx *types.Transaction) (types.Commitment, error) {
+	m.mu.Lock()
+	p, ok := m.pinned[reqId]
+	m.mu.Unlock()
+	if !ok {
+		return types.Commitment{}, fmt.Errorf("%w: %v", ErrUnknownReservation, reqId)
+	}
+
+	commitment, err := p.client.SubmitTransaction(ctx, reqId, tx)
+	if err == nil || errors.Is(err, ErrAlreadySubmitted) {
+		m.mu.Lock()
+		delete(m.pinned, reqId)
+		m.mu.Unlock()
+	}
+	return commitment, err
+}
+
+// Gateway returns client of the gateway, which issued reservation reqId.
+func (m *MultiClient) Gateway(reqId uuid.UUID) (*Client, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	p, ok := m.pinned[reqId]
+	return p.client, ok
+}
@@ -0,0 +1,130 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	u256 "github.com/holiman/uint256"
+
+	"github.com/risechain/luban-api/lubantest"
+	luban "github.com/risechain/luban-api/types"
+)
+
+func TestMultiClient(t *testing.T) {
+	ctx := context.Background()
+	expensive, _, newTx := newMockSetup(t)
+	cheap, _, _ := newMockSetup(t)
+	equal, _, _ := newMockSetup(t)
+	down, _, _ := newMockSetup(t)
+
+	expensive.AddSlot(luban.SlotInfo{Slot: 10, GasAvailable: 30_000_000, BlobsAvailable: 6})
+	expensive.AddSlot(luban.SlotInfo{Slot: 11, GasAvailable: 30_000_000, BlobsAvailable: 6})
+	expensive.SetDefaultFee(lubantest.Fee{GasFee: 10, BlobGasFee: 1})
+	cheap.AddSlot(luban.SlotInfo{Slot: 10, GasAvailable: 20_000_000, BlobsAvailable: 6})
+	cheap.SetDefaultFee(lubantest.Fee{GasFee: 5, BlobGasFee: 1})
+	equal.AddSlot(luban.SlotInfo{Slot: 10, GasAvailable: 10_000_000, BlobsAvailable: 6})
+	equal.SetDefaultFee(lubantest.Fee{GasFee: 5, BlobGasFee: 1})
+	down.FailNext(lubantest.EndpointSlots, http.StatusInternalServerError, lubantest.MsgInternal)
+
+	key, _ := crypto.GenerateKey()
+	signer := NewPrivateKeySigner(key)
+	var clients []*Client
+	for _, g := range []*lubantest.Gateway{expensive, cheap, equal, down} {
+		cl, err := NewClient(g.URL, signer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		clients = append(clients, cl)
+	}
+	multi, err := NewMultiClient(clients...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	slots, err := multi.GetSlots(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(slots) != 2 || slots[0].Slot != 10 || slots[1].Slot != 11 {
+		t.Fatalf("Wrong merged slots: %+v", slots)
+	}
+	if slots[0].GasAvailable != 30_000_000 {
+		t.Fatalf("Merged slot doesn't have the most gas available: %+v", slots[0])
+	}
+
+	gasFee, _, err := multi.GetPreconfFee(ctx, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gasFee != 5 {
+		t.Fatalf("Not the cheapest quote. Have %d, want 5", gasFee)
+	}
+
+	// Cheapest gateway loses the race, so reservation fails over to the
+	// gateway with the same quote, skipping the pricier one
+	cheap.RaceNextReservation()
+	tx := newTx(21000)
+	req := luban.ReserveBlockSpaceRequest{
+		GasLimit:   tx.Gas(),
+		TargetSlot: 10,
+		Deposit:    hexutil.U256(*u256.NewInt(1000)),
+		Tip:        hexutil.U256(*u256.NewInt(100)),
+	}
+	reservation, err := multi.ReserveBlockspace(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	issued, ok := equal.Reservation(reservation.Id)
+	if !ok {
+		t.Fatal("Reservation didn't fail over to the other gateway")
+	}
+	if deposit, tip := (*u256.Int)(&issued.Request.Deposit), (*u256.Int)(&issued.Request.Tip); deposit.Uint64() != 1000 || tip.Uint64() != 100 {
+		t.Fatalf("Reservation repriced. Have deposit %v tip %v, want 1000 and 100", deposit, tip)
+	}
+	if have := expensive.Requests(lubantest.EndpointReserve); have != 0 {
+		t.Fatalf("Reservation failed over to the pricier gateway")
+	}
+
+	if _, err := multi.SubmitTransaction(ctx, reservation.Id, tx); err != nil {
+		t.Fatal(err)
+	}
+	if have := cheap.Requests(lubantest.EndpointSubmit); have != 0 {
+		t.Fatalf("Transaction submitted to the gateway, which didn't issue reservation")
+	}
+	if _, err := multi.SubmitTransaction(ctx, reservation.Id, tx); !errors.Is(err, ErrUnknownReservation) {
+		t.Fatalf("Expected %v, have %v", ErrUnknownReservation, err)
+	}
+
+	// Only the pricier gateway is left, so request has to be priced again
+	cheap.RaceNextReservation()
+	equal.RaceNextReservation()
+	if _, err := multi.ReserveBlockspace(ctx, req); !errors.Is(err, ErrQuoteExceeded) {
+		t.Fatalf("Expected %v, have %v", ErrQuoteExceeded, err)
+	}
+	if have := expensive.Requests(lubantest.EndpointReserve); have != 0 {
+		t.Fatalf("Reservation failed over to the pricier gateway")
+	}
+	cheap.AddSlot(luban.SlotInfo{Slot: 10, GasAvailable: 20_000_000, BlobsAvailable: 6})
+
+	// Abandoned reservations are forgotten, once their slot passes
+	abandoned, err := multi.ReserveBlockspace(ctx, luban.ReserveBlockSpaceRequest{GasLimit: tx.Gas(), TargetSlot: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := multi.Gateway(abandoned.Id); !ok {
+		t.Fatal("Reservation isn't pinned")
+	}
+	expensive.RemoveSlot(10)
+	cheap.RemoveSlot(10)
+	equal.RemoveSlot(10)
+	if _, err := multi.GetSlots(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := multi.Gateway(abandoned.Id); ok {
+		t.Fatal("Reservation for passed slot is still pinned")
+	}
+}
@@ -0,0 +1,97 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/risechain/luban-api/types"
+)
+
+var (
+	// ErrGasLimitExceeded is returned when transaction gas is above reserved gas limit.
+	ErrGasLimitExceeded = errors.New("transaction gas exceeds reserved gas limit")
+	// ErrBlobCountMismatch is returned when transaction has different amount of
+	// blobs, than was reserved.
+	ErrBlobCountMismatch = errors.New("transaction blob count doesn't match reservation")
+	// ErrSlotPassed is returned when reserved slot is already proposed.
+	ErrSlotPassed = errors.New("reserved slot has passed")
+	// ErrNoClient is returned when reservation, which wasn't made by
+	// [Client], is submitted.
+	ErrNoClient = errors.New("reservation has no client to submit through")
+	// ErrNoHeadSource is returned when reservation is submitted through
+	// [Client] without head slot source, so slot can't be checked.
+	ErrNoHeadSource = errors.New("client has no head slot source")
+)
+
+// HeadSlotSource reports head slot of the beacon chain.
+type HeadSlotSource interface {
+	HeadSlot(ctx context.Context) (uint64, error)
+}
+
+// Reservation is blockspace reserved on the gateway, which transaction can be
+// submitted for. Reservations may be built by other clients too, but only
+// the ones made by [Client] can be submitted with [Reservation.Submit].
+type Reservation struct {
+	Id        uuid.UUID
+	Request   types.ReserveBlockSpaceRequest
+	Signature types.LubanSignature
+	// Receipt of the reservation. It carries gateway signature, if gateway
+	// signed the reservation.
+	Receipt types.ReserveBlockSpaceResponse
+
+	client *Client
+}
+
+// TargetSlot returns slot, blockspace was reserved in.
+func (r *Reservation) TargetSlot() uint64 {
+	return r.Request.TargetSlot
+}
+
+// VerifyReceipt checks that gateway signed the reservation. It fails with
+// [types.ErrUnsignedReservation], if gateway returned bare request id.
+func (r *Reservation) VerifyReceipt(gateway common.Address) error {
+	return r.Receipt.Verify(&r.Request, gateway)
+}
+
+// Validate checks that tx fits in the reservation and that reserved slot
+// hasn't passed yet. Slot is checked only if heads isn't nil.
+func (r *Reservation) Validate(ctx context.Context, tx *types.Transaction, heads HeadSlotSource) error {
+	if tx.Gas() > r.Request.GasLimit {
+		return fmt.Errorf("%w: %d > %d", ErrGasLimitExceeded, tx.Gas(), r.Request.GasLimit)
+	}
+	if nBlobs := len(tx.BlobHashes()); nBlobs != int(r.Request.BlobCount) {
+		return fmt.Errorf("%w: have %d, reserved %d", ErrBlobCountMismatch, nBlobs, r.Request.BlobCount)
+	}
+	if heads == nil {
+		return nil
+	}
+	head, err := heads.HeadSlot(ctx)
+	if err != nil {
+		return fmt.Errorf("Failed to get head slot: %w", err)
+	}
+	if head >= r.Request.TargetSlot {
+		return fmt.Errorf("%w: slot %d, head %d", ErrSlotPassed, r.Request.TargetSlot, head)
+	}
+	return nil
+}
+
+// Submit validates tx against the reservation and submits it to the gateway,
+// which issued the reservation. Head slot is taken from the source set with
+// [Client.SetHeadSlotSource], without it Submit fails with [ErrNoHeadSource].
+func (r *Reservation) Submit(ctx context.Context, tx *types.Transaction) (types.Commitment, error) {
+	if r.client == nil {
+		return types.Commitment{}, ErrNoClient
+	}
+	if r.client.headSlot == nil {
+		return types.Commitment{}, ErrNoHeadSource
+	}
+	if err := r.Validate(ctx, tx, r.client.headSlot); err != nil {
+		return types.Commitment{}, err
+	}
+	return r.client.SubmitTransaction(ctx, r.Id, tx)
+}
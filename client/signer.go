@@ -0,0 +1,227 @@
+package client
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+
+	opsigner "github.com/ethereum-optimism/optimism/op-service/signer"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Signer signs requests to the gateway on behalf of the user.
+type Signer interface {
+	// Address of the user, which signs requests.
+	Address() common.Address
+	// SignHash returns 65 byte [R || S || V] signature over hash, V being 0 or 1.
+	SignHash(ctx context.Context, hash common.Hash) ([]byte, error)
+}
+
+// DataSigner is a [Signer], which hashes signed data itself, as
+// [accounts.Wallet] does. Client passes it data of the digest instead of the
+// digest.
+type DataSigner interface {
+	Signer
+	// SignData returns 65 byte [R || S || V] signature over keccak256 of data,
+	// V being 0 or 1.
+	SignData(ctx context.Context, data []byte) ([]byte, error)
+}
+
+var (
+	// ErrHashSigningUnsupported is returned by signers, which can sign only data.
+	ErrHashSigningUnsupported = errors.New("signer can't sign hashes")
+	// ErrSignerMismatch is returned, when signature doesn't recover to the
+	// account of the signer, e.g. because wallet signed prefixed data.
+	ErrSignerMismatch = errors.New("signature doesn't match signer")
+)
+
+// normalizeV converts V of 27 or 28, returned by some signers, to 0 or 1.
+func normalizeV(sig []byte) ([]byte, error) {
+	if len(sig) != crypto.SignatureLength {
+		return nil, fmt.Errorf("signature of length %d", len(sig))
+	}
+	if sig[crypto.RecoveryIDOffset] >= 27 {
+		sig[crypto.RecoveryIDOffset] -= 27
+	}
+	return sig, nil
+}
+
+type privateKeySigner struct {
+	key *ecdsa.PrivateKey
+}
+
+// NewPrivateKeySigner returns signer backed by in-process private key.
+func NewPrivateKeySigner(key *ecdsa.PrivateKey) Signer {
+	return &privateKeySigner{key: key}
+}
+
+func (s *privateKeySigner) Address() common.Address {
+	return crypto.PubkeyToAddress(s.key.PublicKey)
+}
+
+func (s *privateKeySigner) SignHash(_ context.Context, hash common.Hash) ([]byte, error) {
+	return crypto.Sign(hash.Bytes(), s.key)
+}
+
+type keystoreSigner struct {
+	ks         *keystore.KeyStore
+	account    accounts.Account
+	passphrase string
+}
+
+// NewKeystoreSigner returns signer backed by account in go-ethereum keystore.
+// If passphrase is empty, account must be unlocked in the keystore.
+func NewKeystoreSigner(ks *keystore.KeyStore, account accounts.Account, passphrase string) Signer {
+	return &keystoreSigner{ks: ks, account: account, passphrase: passphrase}
+}
+
+func (s *keystoreSigner) Address() common.Address {
+	return s.account.Address
+}
+
+func (s *keystoreSigner) SignHash(_ context.Context, hash common.Hash) ([]byte, error) {
+	if s.passphrase == "" {
+		return s.ks.SignHash(s.account, hash.Bytes())
+	}
+	return s.ks.SignHashWithPassphrase(s.account, s.passphrase, hash.Bytes())
+}
+
+// walletMimetype is passed to [accounts.Wallet.SignData]. Keystore wallets
+// sign keccak256 of data regardless of it.
+const walletMimetype = "application/octet-stream"
+
+type walletSigner struct {
+	wallet     accounts.Wallet
+	account    accounts.Account
+	passphrase string
+}
+
+// NewWalletSigner returns signer backed by account of go-ethereum keystore
+// wallet. Wallet must sign keccak256 of data without any prefix, which
+// external signer (clef) doesn't, as it signs unknown data as EIP-191 text,
+// and USB wallets can't sign data at all. Signatures over other digests fail
+// with [ErrSignerMismatch]. If passphrase is empty, account must be unlocked.
+func NewWalletSigner(wallet accounts.Wallet, account accounts.Account, passphrase string) Signer {
+	return &walletSigner{wallet: wallet, account: account, passphrase: passphrase}
+}
+
+func (s *walletSigner) Address() common.Address {
+	return s.account.Address
+}
+
+func (s *walletSigner) SignHash(context.Context, common.Hash) ([]byte, error) {
+	return nil, ErrHashSigningUnsupported
+}
+
+func (s *walletSigner) SignData(_ context.Context, data []byte) ([]byte, error) {
+	var (
+		sig []byte
+		err error
+	)
+	if s.passphrase == "" {
+		sig, err = s.wallet.SignData(s.account, walletMimetype, data)
+	} else {
+		sig, err = s.wallet.SignDataWithPassphrase(s.account, s.passphrase, walletMimetype, data)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if sig, err = normalizeV(sig); err != nil {
+		return nil, err
+	}
+
+	pub, err := crypto.SigToPub(crypto.Keccak256(data), sig)
+	if err != nil {
+		return nil, err
+	}
+	if signer := crypto.PubkeyToAddress(*pub); signer != s.account.Address {
+		return nil, fmt.Errorf("%w: recovered %v, want %v", ErrSignerMismatch, signer, s.account.Address)
+	}
+	return sig, nil
+}
+
+type remoteSigner struct {
+	client  *rpc.Client
+	address common.Address
+	method  string
+}
+
+// NewRemoteSigner returns signer backed by remote signer service, e.g. the one
+// used by op-service's signer.SignerClient. SignerClient only exposes
+// transaction signing, so hashes are signed by calling method with
+// (address, hash) params over cl, which should be dialed to the same endpoint.
+func NewRemoteSigner(cl *rpc.Client, address common.Address, method string) Signer {
+	return &remoteSigner{client: cl, address: address, method: method}
+}
+
+func (s *remoteSigner) Address() common.Address {
+	return s.address
+}
+
+func (s *remoteSigner) SignHash(ctx context.Context, hash common.Hash) ([]byte, error) {
+	var sig hexutil.Bytes
+	if err := s.client.CallContext(ctx, &sig, s.method, s.address, hash); err != nil {
+		return nil, fmt.Errorf("%s failed: %w", s.method, err)
+	}
+	sig, err := normalizeV(sig)
+	if err != nil {
+		return nil, fmt.Errorf("remote signer returned %w", err)
+	}
+	return sig, nil
+}
+
+// NewSignerClientSigner returns signer backed by the remote signer, which
+// op-service's signer.SignerClient is configured with. SignerClient only
+// signs transactions, so the endpoint is dialed with the same headers and TLS
+// settings, and hashes are signed as by [NewRemoteSigner].
+func NewSignerClientSigner(ctx context.Context, cfg opsigner.CLIConfig, method string) (Signer, error) {
+	if err := cfg.Check(); err != nil {
+		return nil, fmt.Errorf("invalid signer config: %w", err)
+	}
+	if !cfg.Enabled() {
+		return nil, errors.New("signer endpoint and address must be set")
+	}
+	if !common.IsHexAddress(cfg.Address) {
+		return nil, fmt.Errorf("invalid signer address %q", cfg.Address)
+	}
+
+	httpClient := http.DefaultClient
+	if cfg.TLSConfig.TLSEnabled() {
+		caCert, err := os.ReadFile(cfg.TLSConfig.TLSCaCert)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read tls.ca: %w", err)
+		}
+		caCertPool := x509.NewCertPool()
+		caCertPool.AppendCertsFromPEM(caCert)
+		cert, err := tls.LoadX509KeyPair(cfg.TLSConfig.TLSCert, cfg.TLSConfig.TLSKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read tls cert or key: %w", err)
+		}
+		httpClient = &http.Client{
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{
+					MinVersion:   tls.VersionTLS13,
+					RootCAs:      caCertPool,
+					Certificates: []tls.Certificate{cert},
+				},
+			},
+		}
+	}
+
+	cl, err := rpc.DialOptions(ctx, cfg.Endpoint, rpc.WithHTTPClient(httpClient), rpc.WithHeaders(cfg.Headers))
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial signer: %w", err)
+	}
+	return NewRemoteSigner(cl, common.HexToAddress(cfg.Address), method), nil
+}
@@ -0,0 +1,197 @@
+package client
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	opsigner "github.com/ethereum-optimism/optimism/op-service/signer"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/external"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	luban "github.com/risechain/luban-api/types"
+)
+
+// hashSigner is a remote signer service, which returns V as 27 or 28
+type hashSigner struct {
+	key *ecdsa.PrivateKey
+}
+
+func (s *hashSigner) Sign(addr common.Address, hash common.Hash) (hexutil.Bytes, error) {
+	sig, err := crypto.Sign(hash.Bytes(), s.key)
+	if err != nil {
+		return nil, err
+	}
+	sig[crypto.RecoveryIDOffset] += 27
+	return sig, nil
+}
+
+// clefService mimics account API of external signer, which signs data of
+// unknown mimetype as EIP-191 text.
+type clefService struct {
+	key *ecdsa.PrivateKey
+}
+
+func (s *clefService) Version() string {
+	return "7.0.0"
+}
+
+func (s *clefService) List() []common.Address {
+	return []common.Address{crypto.PubkeyToAddress(s.key.PublicKey)}
+}
+
+func (s *clefService) SignData(mimetype string, addr common.MixedcaseAddress, data hexutil.Bytes) (hexutil.Bytes, error) {
+	sig, err := crypto.Sign(accounts.TextHash(data), s.key)
+	if err != nil {
+		return nil, err
+	}
+	sig[crypto.RecoveryIDOffset] += 27
+	return sig, nil
+}
+
+func TestWalletSignerPrefixed(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	srv := rpc.NewServer()
+	if err := srv.RegisterName("account", &clefService{key: key}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(srv.Stop)
+	httpSrv := httptest.NewServer(srv)
+	t.Cleanup(httpSrv.Close)
+
+	wallet, err := external.NewExternalSigner(httpSrv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	account := accounts.Account{Address: crypto.PubkeyToAddress(key.PublicKey)}
+	signer := NewWalletSigner(wallet, account, "").(DataSigner)
+	if _, err := signer.SignData(context.Background(), []byte("data")); !errors.Is(err, ErrSignerMismatch) {
+		t.Fatalf("Expected %v, have %v", ErrSignerMismatch, err)
+	}
+}
+
+func newSignerServer(t *testing.T, key *ecdsa.PrivateKey) *rpc.Server {
+	srv := rpc.NewServer()
+	if err := srv.RegisterName("test", &hashSigner{key: key}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(srv.Stop)
+	return srv
+}
+
+func TestSigners(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	addr := crypto.PubkeyToAddress(key.PublicKey)
+
+	ks := keystore.NewKeyStore(t.TempDir(), keystore.LightScryptN, keystore.LightScryptP)
+	account, err := ks.ImportECDSA(key, "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range []struct {
+		name   string
+		signer func(t *testing.T) Signer
+	}{
+		{
+			name:   "private key",
+			signer: func(t *testing.T) Signer { return NewPrivateKeySigner(key) },
+		},
+		{
+			name:   "keystore",
+			signer: func(t *testing.T) Signer { return NewKeystoreSigner(ks, account, "secret") },
+		},
+		{
+			name: "wallet",
+			signer: func(t *testing.T) Signer {
+				return NewWalletSigner(ks.Wallets()[0], account, "secret")
+			},
+		},
+		{
+			name: "remote",
+			signer: func(t *testing.T) Signer {
+				return NewRemoteSigner(rpc.DialInProc(newSignerServer(t, key)), addr, "test_sign")
+			},
+		},
+		{
+			name: "signer client",
+			signer: func(t *testing.T) Signer {
+				srv := newSignerServer(t, key)
+				httpSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					if r.Header.Get("Authorization") != "Bearer token" {
+						http.Error(w, "unauthorized", http.StatusUnauthorized)
+						return
+					}
+					srv.ServeHTTP(w, r)
+				}))
+				t.Cleanup(httpSrv.Close)
+
+				cfg := opsigner.NewCLIConfig()
+				cfg.Endpoint = httpSrv.URL
+				cfg.Address = addr.Hex()
+				cfg.Headers.Set("Authorization", "Bearer token")
+				cfg.TLSConfig.Enabled = false
+				signer, err := NewSignerClientSigner(context.Background(), cfg, "test_sign")
+				if err != nil {
+					t.Fatal(err)
+				}
+				return signer
+			},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			cl, err := NewClient("http://localhost", test.signer(t))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cl.Address() != addr {
+				t.Fatalf("Wrong signer address. Have %v, want %v", cl.Address(), addr)
+			}
+
+			// Reservations are signed as "<address>:0x<signature>"
+			req := luban.ReserveBlockSpaceRequest{TargetSlot: 10, GasLimit: 21000, BlobCount: 1}
+			sig, err := cl.signReserveBlockspace(ctx, &req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			header := sig.String()
+			if prefix := addr.Hex() + ":0x"; !strings.HasPrefix(header, prefix) || len(header) != len(prefix)+2*crypto.SignatureLength {
+				t.Fatalf("Wrong reservation signature format %q", header)
+			}
+			if signer, err := luban.VerifyReserveBlockspace(&req, header); err != nil || signer != addr {
+				t.Fatalf("Wrong reservation signer %v: %v", signer, err)
+			}
+
+			// Transactions are signed with bare "0x<signature>"
+			chainId := big.NewInt(7028081469)
+			tx := types.MustSignNewTx(key, types.LatestSignerForChainID(chainId), &types.DynamicFeeTx{ChainID: chainId, Gas: 21000})
+			id := uuid.New()
+			sig, err = cl.signSubmitTx(ctx, id, tx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			header = sig.String()
+			if !strings.HasPrefix(header, "0x") || len(header) != 2+2*crypto.SignatureLength {
+				t.Fatalf("Wrong submission signature format %q", header)
+			}
+			if signer, err := luban.VerifySubmitTx(id, tx, header); err != nil || signer != addr {
+				t.Fatalf("Wrong submission signer %v: %v", signer, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/risechain/luban-api/types"
+)
+
+type SlotEventKind int
+
+const (
+	// SlotAppeared is emitted when gateway starts offering a slot
+	SlotAppeared SlotEventKind = iota
+	// SlotCapacityChanged is emitted when gas, blobs or constraints available
+	// in the slot change
+	SlotCapacityChanged
+	// SlotGone is emitted when gateway stops offering a slot
+	SlotGone
+	// SlotPollFailed is emitted when getting slots failed. Slots are kept as
+	// they were until the next successful poll.
+	SlotPollFailed
+)
+
+func (k SlotEventKind) String() string {
+	switch k {
+	case SlotAppeared:
+		return "appeared"
+	case SlotCapacityChanged:
+		return "capacity changed"
+	case SlotGone:
+		return "gone"
+	case SlotPollFailed:
+		return "poll failed"
+	}
+	return fmt.Sprintf("SlotEventKind(%d)", int(k))
+}
+
+type SlotEvent struct {
+	Kind SlotEventKind
+	// Slot is the current state of the slot, or last known state for SlotGone
+	Slot types.SlotInfo
+	// Prev is the previous state of the slot for SlotCapacityChanged
+	Prev *types.SlotInfo
+	// Err is set for SlotPollFailed
+	Err error
+}
+
+// SlotSource is anything, which offers slots, e.g. [Client] or [MultiClient]
+type SlotSource interface {
+	GetSlots(ctx context.Context) ([]types.SlotInfo, error)
+}
+
+// SubscribeSlots polls src every interval and emits changes of the slots. The
+// first poll happens immediately and emits SlotAppeared for every slot. The
+// returned channel is closed once ctx is done.
+func SubscribeSlots(ctx context.Context, src SlotSource, interval time.Duration) <-chan SlotEvent {
+	ch := make(chan SlotEvent)
+	go func() {
+		defer close(ch)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		known := make(map[uint64]types.SlotInfo)
+		for {
+			var events []SlotEvent
+			slots, err := src.GetSlots(ctx)
+			if err != nil {
+				events = []SlotEvent{{Kind: SlotPollFailed, Err: err}}
+			} else {
+				events = diffSlots(known, slots)
+			}
+
+			for _, ev := range events {
+				select {
+				case ch <- ev:
+				case <-ctx.Done():
+					return
+				}
+			}
+
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch
+}
+
+// SubscribeSlots polls gateway for slots. See [SubscribeSlots].
+func (cl *Client) SubscribeSlots(ctx context.Context, interval time.Duration) <-chan SlotEvent {
+	return SubscribeSlots(ctx, cl, interval)
+}
+
+// SubscribeSlots polls all gateways for slots. See [SubscribeSlots].
+func (m *MultiClient) SubscribeSlots(ctx context.Context, interval time.Duration) <-chan SlotEvent {
+	return SubscribeSlots(ctx, m, interval)
+}
+
+func sameCapacity(a, b *types.SlotInfo) bool {
+	if a.GasAvailable != b.GasAvailable || a.BlobsAvailable != b.BlobsAvailable {
+		return false
+	}
+	if a.ConstraintsAvailable == nil || b.ConstraintsAvailable == nil {
+		return a.ConstraintsAvailable == b.ConstraintsAvailable
+	}
+	return *a.ConstraintsAvailable == *b.ConstraintsAvailable
+}
+
+// diffSlots updates known to slots and returns events ordered by slot
+func diffSlots(known map[uint64]types.SlotInfo, slots []types.SlotInfo) []SlotEvent {
+	var events []SlotEvent
+
+	current := make(map[uint64]struct{}, len(slots))
+	for _, s := range slots {
+		current[s.Slot] = struct{}{}
+		prev, ok := known[s.Slot]
+		switch {
+		case !ok:
+			events = append(events, SlotEvent{Kind: SlotAppeared, Slot: s})
+		case !sameCapacity(&prev, &s):
+			events = append(events, SlotEvent{Kind: SlotCapacityChanged, Slot: s, Prev: &prev})
+		}
+		known[s.Slot] = s
+	}
+	for slot, s := range known {
+		if _, ok := current[slot]; !ok {
+			events = append(events, SlotEvent{Kind: SlotGone, Slot: s})
+			delete(known, slot)
+		}
+	}
+
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Slot.Slot < events[j].Slot.Slot })
+	return events
+}
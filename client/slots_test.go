@@ -0,0 +1,60 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/risechain/luban-api/lubantest"
+	luban "github.com/risechain/luban-api/types"
+)
+
+func TestSubscribeSlots(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	gateway, preconfer, _ := newMockSetup(t)
+	gateway.AddSlot(luban.SlotInfo{Slot: 10, GasAvailable: 30_000_000, BlobsAvailable: 6})
+	gateway.AddSlot(luban.SlotInfo{Slot: 11, GasAvailable: 30_000_000, BlobsAvailable: 6})
+
+	events := preconfer.SubscribeSlots(ctx, 10*time.Millisecond)
+	next := func(kind SlotEventKind, slot uint64) SlotEvent {
+		t.Helper()
+		select {
+		case ev := <-events:
+			if ev.Kind != kind || ev.Slot.Slot != slot {
+				t.Fatalf("Wrong event. Have %v for slot %d, want %v for slot %d", ev.Kind, ev.Slot.Slot, kind, slot)
+			}
+			return ev
+		case <-time.After(time.Second):
+			t.Fatalf("No %v event for slot %d", kind, slot)
+		}
+		return SlotEvent{}
+	}
+
+	next(SlotAppeared, 10)
+	next(SlotAppeared, 11)
+
+	_, err := preconfer.ReserveBlockspace(ctx, luban.ReserveBlockSpaceRequest{GasLimit: 21000, TargetSlot: 11})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ev := next(SlotCapacityChanged, 11)
+	if ev.Prev.GasAvailable != 30_000_000 || ev.Slot.GasAvailable != 30_000_000-21000 {
+		t.Fatalf("Wrong capacity change: %+v -> %+v", *ev.Prev, ev.Slot)
+	}
+
+	gateway.FailNext(lubantest.EndpointSlots, http.StatusInternalServerError, lubantest.MsgInternal)
+	if ev := next(SlotPollFailed, 0); ev.Err == nil {
+		t.Fatal("No error for failed poll")
+	}
+
+	gateway.RemoveSlot(10)
+	gateway.AddSlot(luban.SlotInfo{Slot: 12, GasAvailable: 30_000_000, BlobsAvailable: 6})
+	next(SlotGone, 10)
+	next(SlotAppeared, 12)
+
+	cancel()
+	for range events {
+	}
+}
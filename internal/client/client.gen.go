@@ -25,6 +25,17 @@ type PreconfFeeResponse struct {
 	GasFee     uint64 `json:"gas_fee"`
 }
 
+// ReservationId defines model for ReservationId.
+type ReservationId = openapi_types.UUID
+
+// ReservationReceipt defines model for ReservationReceipt.
+type ReservationReceipt struct {
+	RequestId openapi_types.UUID `json:"request_id"`
+
+	// Signature An ECDSA signature from the gateway over keccak256 of request id as little endian bytes concatenated with digest of the reservation request.
+	Signature string `json:"signature"`
+}
+
 // ReserveBlockSpaceRequest defines model for ReserveBlockSpaceRequest.
 type ReserveBlockSpaceRequest struct {
 	BlobCount uint32 `json:"blob_count"`
@@ -44,8 +55,10 @@ type ReserveBlockSpaceRequest struct {
 	Tip geth_hexutil.U256 `json:"tip"`
 }
 
-// ReserveBlockSpaceResponse defines model for ReserveBlockSpaceResponse.
-type ReserveBlockSpaceResponse = openapi_types.UUID
+// ReserveBlockSpaceResponse Either bare request id or reservation receipt signed by the gateway
+type ReserveBlockSpaceResponse struct {
+	union json.RawMessage
+}
 
 // SlotInfo defines model for SlotInfo.
 type SlotInfo struct {
@@ -100,6 +113,68 @@ type ReserveBlockspaceJSONRequestBody = ReserveBlockSpaceRequest
 // SubmitTransactionJSONRequestBody defines body for SubmitTransaction for application/json ContentType.
 type SubmitTransactionJSONRequestBody = SubmitTransactionRequest
 
+// AsReservationId returns the union data inside the ReserveBlockSpaceResponse as a ReservationId
+func (t ReserveBlockSpaceResponse) AsReservationId() (ReservationId, error) {
+	var body ReservationId
+	err := json.Unmarshal(t.union, &body)
+	return body, err
+}
+
+// FromReservationId overwrites any union data inside the ReserveBlockSpaceResponse as the provided ReservationId
+func (t *ReserveBlockSpaceResponse) FromReservationId(v ReservationId) error {
+	b, err := json.Marshal(v)
+	t.union = b
+	return err
+}
+
+// MergeReservationId performs a merge with any union data inside the ReserveBlockSpaceResponse, using the provided ReservationId
+func (t *ReserveBlockSpaceResponse) MergeReservationId(v ReservationId) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	merged, err := runtime.JSONMerge(t.union, b)
+	t.union = merged
+	return err
+}
+
+// AsReservationReceipt returns the union data inside the ReserveBlockSpaceResponse as a ReservationReceipt
+func (t ReserveBlockSpaceResponse) AsReservationReceipt() (ReservationReceipt, error) {
+	var body ReservationReceipt
+	err := json.Unmarshal(t.union, &body)
+	return body, err
+}
+
+// FromReservationReceipt overwrites any union data inside the ReserveBlockSpaceResponse as the provided ReservationReceipt
+func (t *ReserveBlockSpaceResponse) FromReservationReceipt(v ReservationReceipt) error {
+	b, err := json.Marshal(v)
+	t.union = b
+	return err
+}
+
+// MergeReservationReceipt performs a merge with any union data inside the ReserveBlockSpaceResponse, using the provided ReservationReceipt
+func (t *ReserveBlockSpaceResponse) MergeReservationReceipt(v ReservationReceipt) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	merged, err := runtime.JSONMerge(t.union, b)
+	t.union = merged
+	return err
+}
+
+func (t ReserveBlockSpaceResponse) MarshalJSON() ([]byte, error) {
+	b, err := t.union.MarshalJSON()
+	return b, err
+}
+
+func (t *ReserveBlockSpaceResponse) UnmarshalJSON(b []byte) error {
+	err := t.union.UnmarshalJSON(b)
+	return err
+}
+
 // RequestEditorFn  is the function signature for the RequestEditor callback function
 type RequestEditorFn func(ctx context.Context, req *http.Request) error
 
@@ -0,0 +1,393 @@
+// Package lubantest provides in-process Taiyi gateway for testing code, which
+// interacts with the gateway, without network access.
+package lubantest
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/risechain/luban-api/server"
+	"github.com/risechain/luban-api/types"
+)
+
+// Endpoint is a path of the gateway API
+type Endpoint string
+
+const (
+	EndpointSlots   Endpoint = "/commitments/v0/slots"
+	EndpointFee     Endpoint = "/commitments/v0/preconf_fee"
+	EndpointReserve Endpoint = "/commitments/v0/reserve_blockspace"
+	EndpointSubmit  Endpoint = "/commitments/v0/submit_transaction"
+)
+
+const (
+	MsgBlockspaceUnavailable = "requested blockspace not available for slot"
+	MsgAlreadySubmitted      = "Transaction for this request id was already submitted"
+	MsgInvalidSignature      = "Invalid signature"
+	MsgUnknownRequest        = "Unknown request id"
+	MsgInternal              = "internal server error"
+)
+
+// Fee is a preconf fee quote for a slot, denominated in wei
+type Fee struct {
+	GasFee     uint64
+	BlobGasFee uint64
+}
+
+// Reservation is blockspace reserved by a user
+type Reservation struct {
+	Id      uuid.UUID
+	Request types.ReserveBlockSpaceRequest
+	Signer  common.Address
+	// Tx is nil, until transaction is submitted
+	Tx *types.Transaction
+}
+
+type failure struct {
+	status  int
+	message string
+}
+
+// Gateway is a mock of Taiyi gateway served over [httptest.Server].
+//
+// It verifies x-luban-signature headers, tracks slot capacity and returns
+// commitments signed with its key. Tests can script its behaviour by
+// injecting failures, slot races and latency.
+type Gateway struct {
+	*httptest.Server
+
+	key *ecdsa.PrivateKey
+
+	mu           sync.Mutex
+	slots        map[uint64]*types.SlotInfo
+	fees         map[uint64]Fee
+	defaultFee   Fee
+	reservations map[uuid.UUID]*Reservation
+	failures     map[Endpoint][]failure
+	races        int
+	unsigned     bool
+	latency      map[Endpoint]time.Duration
+	requests     map[Endpoint]int
+}
+
+// NewGateway starts gateway, which signs commitments with key. Gateway has no
+// slots until they are added with [Gateway.AddSlot].
+func NewGateway(key *ecdsa.PrivateKey) *Gateway {
+	g := &Gateway{
+		key:          key,
+		slots:        make(map[uint64]*types.SlotInfo),
+		fees:         make(map[uint64]Fee),
+		reservations: make(map[uuid.UUID]*Reservation),
+		failures:     make(map[Endpoint][]failure),
+		latency:      make(map[Endpoint]time.Duration),
+		requests:     make(map[Endpoint]int),
+	}
+
+	strict := server.NewStrictHandlerWithOptions(&handler{g: g}, nil, server.StrictHTTPServerOptions{
+		RequestErrorHandlerFunc:  badRequest,
+		ResponseErrorHandlerFunc: internalError,
+	})
+	g.Server = httptest.NewServer(server.HandlerWithOptions(strict, server.StdHTTPServerOptions{
+		Middlewares:      []server.MiddlewareFunc{g.middleware},
+		ErrorHandlerFunc: badRequest,
+	}))
+
+	return g
+}
+
+// Address returns address of the gateway, which signs commitments.
+func (g *Gateway) Address() common.Address {
+	return crypto.PubkeyToAddress(g.key.PublicKey)
+}
+
+// AddSlot adds slot or replaces capacity of existing one.
+func (g *Gateway) AddSlot(slot types.SlotInfo) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if slot.ConstraintsAvailable != nil {
+		constraints := *slot.ConstraintsAvailable
+		slot.ConstraintsAvailable = &constraints
+	}
+	g.slots[slot.Slot] = &slot
+}
+
+// RemoveSlot removes slot, e.g. once it has passed.
+func (g *Gateway) RemoveSlot(slot uint64) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	delete(g.slots, slot)
+}
+
+// Slot returns current capacity of the slot.
+func (g *Gateway) Slot(slot uint64) (types.SlotInfo, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	s, ok := g.slots[slot]
+	if !ok {
+		return types.SlotInfo{}, false
+	}
+	return *s, true
+}
+
+// SetFee sets fee quoted for the slot.
+func (g *Gateway) SetFee(slot uint64, fee Fee) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.fees[slot] = fee
+}
+
+// SetDefaultFee sets fee quoted for slots without fee set by [Gateway.SetFee].
+func (g *Gateway) SetDefaultFee(fee Fee) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.defaultFee = fee
+}
+
+// FailNext makes the next request to the endpoint fail with status and
+// message. Calls are queued, so failing several requests in a row requires
+// calling it several times.
+func (g *Gateway) FailNext(ep Endpoint, status int, message string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.failures[ep] = append(g.failures[ep], failure{status: status, message: message})
+}
+
+// RaceNextReservation simulates someone else reserving all remaining capacity
+// of the target slot right before the next reservation is processed.
+func (g *Gateway) RaceNextReservation() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.races++
+}
+
+// SetUnsignedReservations makes gateway return bare request id instead of
+// signed reservation receipt, as older gateways do.
+func (g *Gateway) SetUnsignedReservations(unsigned bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.unsigned = unsigned
+}
+
+// SetLatency delays responses of the endpoint by d.
+func (g *Gateway) SetLatency(ep Endpoint, d time.Duration) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.latency[ep] = d
+}
+
+// Requests returns number of requests made to the endpoint.
+func (g *Gateway) Requests(ep Endpoint) int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.requests[ep]
+}
+
+// Reservation returns reservation made with id.
+func (g *Gateway) Reservation(id uuid.UUID) (Reservation, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	r, ok := g.reservations[id]
+	if !ok {
+		return Reservation{}, false
+	}
+	return *r, true
+}
+
+// Reservations returns all reservations made so far.
+func (g *Gateway) Reservations() []Reservation {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	res := make([]Reservation, 0, len(g.reservations))
+	for _, r := range g.reservations {
+		res = append(res, *r)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Request.TargetSlot < res[j].Request.TargetSlot })
+	return res
+}
+
+// middleware records requests and applies scripted latency and failures
+func (g *Gateway) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ep := Endpoint(r.URL.Path)
+		g.mu.Lock()
+		g.requests[ep]++
+		latency := g.latency[ep]
+		var fail *failure
+		if failures := g.failures[ep]; len(failures) > 0 {
+			fail = &failures[0]
+			g.failures[ep] = failures[1:]
+		}
+		g.mu.Unlock()
+
+		if latency > 0 {
+			select {
+			case <-time.After(latency):
+			case <-r.Context().Done():
+				return
+			}
+		}
+		if fail != nil {
+			writeError(w, fail.status, fail.message)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	}{Code: status, Message: message})
+}
+
+func badRequest(w http.ResponseWriter, r *http.Request, err error) {
+	writeError(w, http.StatusBadRequest, err.Error())
+}
+
+func internalError(w http.ResponseWriter, r *http.Request, err error) {
+	writeError(w, http.StatusInternalServerError, err.Error())
+}
+
+// handler implements gateway API on top of [Gateway] state
+type handler struct {
+	g *Gateway
+}
+
+var _ server.StrictServerInterface = (*handler)(nil)
+
+func (h *handler) GetSlots(ctx context.Context, req server.GetSlotsRequestObject) (server.GetSlotsResponseObject, error) {
+	h.g.mu.Lock()
+	slots := make(server.GetSlots200JSONResponse, 0, len(h.g.slots))
+	for _, s := range h.g.slots {
+		slots = append(slots, server.SlotInfo(*s))
+	}
+	h.g.mu.Unlock()
+
+	sort.Slice(slots, func(i, j int) bool { return slots[i].Slot < slots[j].Slot })
+	return slots, nil
+}
+
+func (h *handler) GetFee(ctx context.Context, req server.GetFeeRequestObject) (server.GetFeeResponseObject, error) {
+	h.g.mu.Lock()
+	defer h.g.mu.Unlock()
+
+	fee, ok := h.g.fees[*req.Body]
+	if !ok {
+		fee = h.g.defaultFee
+	}
+	return server.GetFee200JSONResponse{GasFee: fee.GasFee, BlobGasFee: fee.BlobGasFee}, nil
+}
+
+func reserveError(message string) server.ReserveBlockspace400JSONResponse {
+	return server.ReserveBlockspace400JSONResponse{Code: http.StatusBadRequest, Message: message}
+}
+
+func (h *handler) ReserveBlockspace(ctx context.Context, request server.ReserveBlockspaceRequestObject) (server.ReserveBlockspaceResponseObject, error) {
+	req := types.ReserveBlockSpaceRequest(*request.Body)
+	signer, err := types.VerifyReserveBlockspace(&req, request.Params.XLubanSignature)
+	if err != nil {
+		return reserveError(fmt.Sprintf("%s: %v", MsgInvalidSignature, err)), nil
+	}
+
+	h.g.mu.Lock()
+	defer h.g.mu.Unlock()
+
+	slot, ok := h.g.slots[req.TargetSlot]
+	if ok && h.g.races > 0 {
+		h.g.races--
+		slot.GasAvailable = 0
+		slot.BlobsAvailable = 0
+	}
+	if !ok || slot.GasAvailable < req.GasLimit || slot.BlobsAvailable < req.BlobCount ||
+		(slot.ConstraintsAvailable != nil && *slot.ConstraintsAvailable == 0) {
+		return reserveError(MsgBlockspaceUnavailable), nil
+	}
+	slot.GasAvailable -= req.GasLimit
+	slot.BlobsAvailable -= req.BlobCount
+	if slot.ConstraintsAvailable != nil {
+		*slot.ConstraintsAvailable--
+	}
+
+	id := uuid.New()
+	h.g.reservations[id] = &Reservation{Id: id, Request: req, Signer: signer}
+
+	var resp server.ReserveBlockSpaceResponse
+	if h.g.unsigned {
+		err = resp.FromReservationId(id)
+	} else {
+		var sig []byte
+		if sig, err = crypto.Sign(types.ReservationDigest(id, &req).Bytes(), h.g.key); err != nil {
+			return nil, err
+		}
+		err = resp.FromReservationReceipt(server.ReservationReceipt{
+			RequestId: id,
+			Signature: hexutil.Encode(sig),
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+	return server.ReserveBlockspace200JSONResponse(resp), nil
+}
+
+func submitError(message string) server.SubmitTransaction400JSONResponse {
+	return server.SubmitTransaction400JSONResponse{Code: http.StatusBadRequest, Message: message}
+}
+
+func (h *handler) SubmitTransaction(ctx context.Context, request server.SubmitTransactionRequestObject) (server.SubmitTransactionResponseObject, error) {
+	req := request.Body
+	if req.Transaction == nil {
+		return submitError("missing transaction"), nil
+	}
+	signer, err := types.VerifySubmitTx(req.RequestId, req.Transaction, request.Params.XLubanSignature)
+	if err != nil {
+		return submitError(fmt.Sprintf("%s: %v", MsgInvalidSignature, err)), nil
+	}
+
+	h.g.mu.Lock()
+	defer h.g.mu.Unlock()
+
+	reservation, ok := h.g.reservations[req.RequestId]
+	if !ok {
+		return submitError(MsgUnknownRequest), nil
+	}
+	if signer != reservation.Signer {
+		return submitError(fmt.Sprintf("%s: signed by %v, not %v", MsgInvalidSignature, signer, reservation.Signer)), nil
+	}
+	if reservation.Tx != nil {
+		return submitError(MsgAlreadySubmitted), nil
+	}
+
+	sig, err := crypto.Sign(types.SubmitTxDigest(req.RequestId, req.Transaction).Bytes(), h.g.key)
+	if err != nil {
+		return nil, err
+	}
+	reservation.Tx = req.Transaction
+
+	var resp server.SubmitTransaction200JSONResponse
+	resp.Status = "success"
+	resp.Message = "transaction submitted"
+	resp.Data.RequestId = req.RequestId
+	resp.Data.Commitment.R = "0x" + hex.EncodeToString(sig[:32])
+	resp.Data.Commitment.S = "0x" + hex.EncodeToString(sig[32:64])
+	resp.Data.Commitment.V = hexutil.EncodeUint64(uint64(sig[64]))
+	resp.Data.Commitment.YParity = hexutil.EncodeUint64(uint64(sig[64]))
+	return resp, nil
+}
@@ -0,0 +1,2 @@
+//go:generate go run -modfile=../tools/go.mod github.com/oapi-codegen/oapi-codegen/v2/cmd/oapi-codegen --config=config.yaml ../internal/client/openapi.yaml
+package server
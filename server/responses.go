@@ -0,0 +1,9 @@
+package server
+
+import "encoding/json"
+
+// MarshalJSON encodes the response as [ReserveBlockSpaceResponse]. Generated
+// response is a defined type over it, which loses its JSON encoding.
+func (response ReserveBlockspace200JSONResponse) MarshalJSON() ([]byte, error) {
+	return json.Marshal(ReserveBlockSpaceResponse(response))
+}
@@ -0,0 +1,754 @@
+//go:build go1.22
+
+// Package server provides primitives to interact with the openapi HTTP API.
+//
+// Code generated by github.com/oapi-codegen/oapi-codegen/v2 version v2.4.1 DO NOT EDIT.
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	geth_hexutil "github.com/ethereum/go-ethereum/common/hexutil"
+	geth_core_types "github.com/ethereum/go-ethereum/core/types"
+	"github.com/oapi-codegen/runtime"
+	strictnethttp "github.com/oapi-codegen/runtime/strictmiddleware/nethttp"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+// PreconfFeeResponse denominated in wei
+type PreconfFeeResponse struct {
+	BlobGasFee uint64 `json:"blob_gas_fee"`
+	GasFee     uint64 `json:"gas_fee"`
+}
+
+// ReservationId defines model for ReservationId.
+type ReservationId = openapi_types.UUID
+
+// ReservationReceipt defines model for ReservationReceipt.
+type ReservationReceipt struct {
+	RequestId openapi_types.UUID `json:"request_id"`
+
+	// Signature An ECDSA signature from the gateway over keccak256 of request id as little endian bytes concatenated with digest of the reservation request.
+	Signature string `json:"signature"`
+}
+
+// ReserveBlockSpaceRequest defines model for ReserveBlockSpaceRequest.
+type ReserveBlockSpaceRequest struct {
+	BlobCount uint32 `json:"blob_count"`
+
+	// Deposit This is the amount deducted from the user's escrow balance when the user fails to submit a transaction for the allocated blockspace.
+	//
+	// The deposit is calculated as follows:
+	// { gas_limit * gas_fee + blob_count * blob_gas_fee } * 0.5
+	Deposit    geth_hexutil.U256 `json:"deposit"`
+	GasLimit   uint64            `json:"gas_limit"`
+	TargetSlot uint64            `json:"target_slot"`
+
+	// Tip This is the amount deducted from the user's escrow balance along with `[deposit]` when the user submits a transaction for the allocated blockspace.
+	//
+	// The tip is calculated as follows:
+	// { gas_limit * gas_fee + blob_count * blob_gas_fee } * 0.5
+	Tip geth_hexutil.U256 `json:"tip"`
+}
+
+// ReserveBlockSpaceResponse Either bare request id or reservation receipt signed by the gateway
+type ReserveBlockSpaceResponse struct {
+	union json.RawMessage
+}
+
+// SlotInfo defines model for SlotInfo.
+type SlotInfo struct {
+	BlobsAvailable       uint32  `json:"blobs_available"`
+	ConstraintsAvailable *uint32 `json:"constraints_available,omitempty"`
+	GasAvailable         uint64  `json:"gas_available"`
+	Slot                 uint64  `json:"slot"`
+}
+
+// SubmitTransactionRequest defines model for SubmitTransactionRequest.
+type SubmitTransactionRequest struct {
+	RequestId   openapi_types.UUID           `json:"request_id"`
+	Transaction *geth_core_types.Transaction `json:"transaction"`
+}
+
+// SubmitTxResponse defines model for SubmitTxResponse.
+type SubmitTxResponse struct {
+	Data struct {
+		Commitment struct {
+			R       string `json:"r"`
+			S       string `json:"s"`
+			V       string `json:"v"`
+			YParity string `json:"yParity"`
+		} `json:"commitment"`
+		RequestId openapi_types.UUID `json:"request_id"`
+	} `json:"data"`
+	Message string `json:"message"`
+	Status  string `json:"status"`
+}
+
+// GetFeeJSONBody defines parameters for GetFee.
+type GetFeeJSONBody = uint64
+
+// ReserveBlockspaceParams defines parameters for ReserveBlockspace.
+type ReserveBlockspaceParams struct {
+	// XLubanSignature An ECDSA signature from the user over fields of request body
+	XLubanSignature string `json:"x-luban-signature"`
+}
+
+// SubmitTransactionParams defines parameters for SubmitTransaction.
+type SubmitTransactionParams struct {
+	// XLubanSignature An ECDSA signature from the user over fields of body.
+	XLubanSignature string `json:"x-luban-signature"`
+}
+
+// GetFeeJSONRequestBody defines body for GetFee for application/json ContentType.
+type GetFeeJSONRequestBody = GetFeeJSONBody
+
+// ReserveBlockspaceJSONRequestBody defines body for ReserveBlockspace for application/json ContentType.
+type ReserveBlockspaceJSONRequestBody = ReserveBlockSpaceRequest
+
+// SubmitTransactionJSONRequestBody defines body for SubmitTransaction for application/json ContentType.
+type SubmitTransactionJSONRequestBody = SubmitTransactionRequest
+
+// AsReservationId returns the union data inside the ReserveBlockSpaceResponse as a ReservationId
+func (t ReserveBlockSpaceResponse) AsReservationId() (ReservationId, error) {
+	var body ReservationId
+	err := json.Unmarshal(t.union, &body)
+	return body, err
+}
+
+// FromReservationId overwrites any union data inside the ReserveBlockSpaceResponse as the provided ReservationId
+func (t *ReserveBlockSpaceResponse) FromReservationId(v ReservationId) error {
+	b, err := json.Marshal(v)
+	t.union = b
+	return err
+}
+
+// MergeReservationId performs a merge with any union data inside the ReserveBlockSpaceResponse, using the provided ReservationId
+func (t *ReserveBlockSpaceResponse) MergeReservationId(v ReservationId) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	merged, err := runtime.JSONMerge(t.union, b)
+	t.union = merged
+	return err
+}
+
+// AsReservationReceipt returns the union data inside the ReserveBlockSpaceResponse as a ReservationReceipt
+func (t ReserveBlockSpaceResponse) AsReservationReceipt() (ReservationReceipt, error) {
+	var body ReservationReceipt
+	err := json.Unmarshal(t.union, &body)
+	return body, err
+}
+
+// FromReservationReceipt overwrites any union data inside the ReserveBlockSpaceResponse as the provided ReservationReceipt
+func (t *ReserveBlockSpaceResponse) FromReservationReceipt(v ReservationReceipt) error {
+	b, err := json.Marshal(v)
+	t.union = b
+	return err
+}
+
+// MergeReservationReceipt performs a merge with any union data inside the ReserveBlockSpaceResponse, using the provided ReservationReceipt
+func (t *ReserveBlockSpaceResponse) MergeReservationReceipt(v ReservationReceipt) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	merged, err := runtime.JSONMerge(t.union, b)
+	t.union = merged
+	return err
+}
+
+func (t ReserveBlockSpaceResponse) MarshalJSON() ([]byte, error) {
+	b, err := t.union.MarshalJSON()
+	return b, err
+}
+
+func (t *ReserveBlockSpaceResponse) UnmarshalJSON(b []byte) error {
+	err := t.union.UnmarshalJSON(b)
+	return err
+}
+
+// ServerInterface represents all server handlers.
+type ServerInterface interface {
+	// Returns a fee quoted in "wei" per gas
+	// (POST /commitments/v0/preconf_fee)
+	GetFee(w http.ResponseWriter, r *http.Request)
+	// Reserves blockspace for a slot
+	// (POST /commitments/v0/reserve_blockspace)
+	ReserveBlockspace(w http.ResponseWriter, r *http.Request, params ReserveBlockspaceParams)
+	// Get a list of slots which are available in the current and next epoch
+	// (GET /commitments/v0/slots)
+	GetSlots(w http.ResponseWriter, r *http.Request)
+	// Used to submit transaction
+	// (POST /commitments/v0/submit_transaction)
+	SubmitTransaction(w http.ResponseWriter, r *http.Request, params SubmitTransactionParams)
+}
+
+// ServerInterfaceWrapper converts contexts to parameters.
+type ServerInterfaceWrapper struct {
+	Handler            ServerInterface
+	HandlerMiddlewares []MiddlewareFunc
+	ErrorHandlerFunc   func(w http.ResponseWriter, r *http.Request, err error)
+}
+
+type MiddlewareFunc func(http.Handler) http.Handler
+
+// GetFee operation middleware
+func (siw *ServerInterfaceWrapper) GetFee(w http.ResponseWriter, r *http.Request) {
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetFee(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// ReserveBlockspace operation middleware
+func (siw *ServerInterfaceWrapper) ReserveBlockspace(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ReserveBlockspaceParams
+
+	headers := r.Header
+
+	// ------------- Required header parameter "x-luban-signature" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("x-luban-signature")]; found {
+		var XLubanSignature string
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandlerFunc(w, r, &TooManyValuesForParamError{ParamName: "x-luban-signature", Count: n})
+			return
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "x-luban-signature", valueList[0], &XLubanSignature, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: true})
+		if err != nil {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "x-luban-signature", Err: err})
+			return
+		}
+
+		params.XLubanSignature = XLubanSignature
+
+	} else {
+		err := fmt.Errorf("Header parameter x-luban-signature is required, but not found")
+		siw.ErrorHandlerFunc(w, r, &RequiredHeaderError{ParamName: "x-luban-signature", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.ReserveBlockspace(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// GetSlots operation middleware
+func (siw *ServerInterfaceWrapper) GetSlots(w http.ResponseWriter, r *http.Request) {
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetSlots(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// SubmitTransaction operation middleware
+func (siw *ServerInterfaceWrapper) SubmitTransaction(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params SubmitTransactionParams
+
+	headers := r.Header
+
+	// ------------- Required header parameter "x-luban-signature" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("x-luban-signature")]; found {
+		var XLubanSignature string
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandlerFunc(w, r, &TooManyValuesForParamError{ParamName: "x-luban-signature", Count: n})
+			return
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "x-luban-signature", valueList[0], &XLubanSignature, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: true})
+		if err != nil {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "x-luban-signature", Err: err})
+			return
+		}
+
+		params.XLubanSignature = XLubanSignature
+
+	} else {
+		err := fmt.Errorf("Header parameter x-luban-signature is required, but not found")
+		siw.ErrorHandlerFunc(w, r, &RequiredHeaderError{ParamName: "x-luban-signature", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.SubmitTransaction(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+type UnescapedCookieParamError struct {
+	ParamName string
+	Err       error
+}
+
+func (e *UnescapedCookieParamError) Error() string {
+	return fmt.Sprintf("error unescaping cookie parameter '%s'", e.ParamName)
+}
+
+func (e *UnescapedCookieParamError) Unwrap() error {
+	return e.Err
+}
+
+type UnmarshalingParamError struct {
+	ParamName string
+	Err       error
+}
+
+func (e *UnmarshalingParamError) Error() string {
+	return fmt.Sprintf("Error unmarshaling parameter %s as JSON: %s", e.ParamName, e.Err.Error())
+}
+
+func (e *UnmarshalingParamError) Unwrap() error {
+	return e.Err
+}
+
+type RequiredParamError struct {
+	ParamName string
+}
+
+func (e *RequiredParamError) Error() string {
+	return fmt.Sprintf("Query argument %s is required, but not found", e.ParamName)
+}
+
+type RequiredHeaderError struct {
+	ParamName string
+	Err       error
+}
+
+func (e *RequiredHeaderError) Error() string {
+	return fmt.Sprintf("Header parameter %s is required, but not found", e.ParamName)
+}
+
+func (e *RequiredHeaderError) Unwrap() error {
+	return e.Err
+}
+
+type InvalidParamFormatError struct {
+	ParamName string
+	Err       error
+}
+
+func (e *InvalidParamFormatError) Error() string {
+	return fmt.Sprintf("Invalid format for parameter %s: %s", e.ParamName, e.Err.Error())
+}
+
+func (e *InvalidParamFormatError) Unwrap() error {
+	return e.Err
+}
+
+type TooManyValuesForParamError struct {
+	ParamName string
+	Count     int
+}
+
+func (e *TooManyValuesForParamError) Error() string {
+	return fmt.Sprintf("Expected one value for %s, got %d", e.ParamName, e.Count)
+}
+
+// Handler creates http.Handler with routing matching OpenAPI spec.
+func Handler(si ServerInterface) http.Handler {
+	return HandlerWithOptions(si, StdHTTPServerOptions{})
+}
+
+// ServeMux is an abstraction of http.ServeMux.
+type ServeMux interface {
+	HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request))
+	ServeHTTP(w http.ResponseWriter, r *http.Request)
+}
+
+type StdHTTPServerOptions struct {
+	BaseURL          string
+	BaseRouter       ServeMux
+	Middlewares      []MiddlewareFunc
+	ErrorHandlerFunc func(w http.ResponseWriter, r *http.Request, err error)
+}
+
+// HandlerFromMux creates http.Handler with routing matching OpenAPI spec based on the provided mux.
+func HandlerFromMux(si ServerInterface, m ServeMux) http.Handler {
+	return HandlerWithOptions(si, StdHTTPServerOptions{
+		BaseRouter: m,
+	})
+}
+
+func HandlerFromMuxWithBaseURL(si ServerInterface, m ServeMux, baseURL string) http.Handler {
+	return HandlerWithOptions(si, StdHTTPServerOptions{
+		BaseURL:    baseURL,
+		BaseRouter: m,
+	})
+}
+
+// HandlerWithOptions creates http.Handler with additional options
+func HandlerWithOptions(si ServerInterface, options StdHTTPServerOptions) http.Handler {
+	m := options.BaseRouter
+
+	if m == nil {
+		m = http.NewServeMux()
+	}
+	if options.ErrorHandlerFunc == nil {
+		options.ErrorHandlerFunc = func(w http.ResponseWriter, r *http.Request, err error) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		}
+	}
+
+	wrapper := ServerInterfaceWrapper{
+		Handler:            si,
+		HandlerMiddlewares: options.Middlewares,
+		ErrorHandlerFunc:   options.ErrorHandlerFunc,
+	}
+
+	m.HandleFunc("POST "+options.BaseURL+"/commitments/v0/preconf_fee", wrapper.GetFee)
+	m.HandleFunc("POST "+options.BaseURL+"/commitments/v0/reserve_blockspace", wrapper.ReserveBlockspace)
+	m.HandleFunc("GET "+options.BaseURL+"/commitments/v0/slots", wrapper.GetSlots)
+	m.HandleFunc("POST "+options.BaseURL+"/commitments/v0/submit_transaction", wrapper.SubmitTransaction)
+
+	return m
+}
+
+type GetFeeRequestObject struct {
+	Body *GetFeeJSONRequestBody
+}
+
+type GetFeeResponseObject interface {
+	VisitGetFeeResponse(w http.ResponseWriter) error
+}
+
+type GetFee200JSONResponse PreconfFeeResponse
+
+func (response GetFee200JSONResponse) VisitGetFeeResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetFee500JSONResponse struct {
+	// Code Either specific error code in case of invalid request or http status code
+	Code *float32 `json:"code,omitempty"`
+
+	// Message Message describing error
+	Message *string `json:"message,omitempty"`
+}
+
+func (response GetFee500JSONResponse) VisitGetFeeResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(500)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type ReserveBlockspaceRequestObject struct {
+	Params ReserveBlockspaceParams
+	Body   *ReserveBlockspaceJSONRequestBody
+}
+
+type ReserveBlockspaceResponseObject interface {
+	VisitReserveBlockspaceResponse(w http.ResponseWriter) error
+}
+
+type ReserveBlockspace200JSONResponse ReserveBlockSpaceResponse
+
+func (response ReserveBlockspace200JSONResponse) VisitReserveBlockspaceResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type ReserveBlockspace400JSONResponse struct {
+	// Code Either specific error code in case of invalid request or http status code
+	Code float32 `json:"code"`
+
+	// Message Message describing error
+	Message string `json:"message"`
+}
+
+func (response ReserveBlockspace400JSONResponse) VisitReserveBlockspaceResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(400)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type ReserveBlockspace500JSONResponse struct {
+	// Code Either specific error code in case of invalid request or http status code
+	Code *float32 `json:"code,omitempty"`
+
+	// Message Message describing error
+	Message *string `json:"message,omitempty"`
+}
+
+func (response ReserveBlockspace500JSONResponse) VisitReserveBlockspaceResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(500)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetSlotsRequestObject struct {
+}
+
+type GetSlotsResponseObject interface {
+	VisitGetSlotsResponse(w http.ResponseWriter) error
+}
+
+type GetSlots200JSONResponse []SlotInfo
+
+func (response GetSlots200JSONResponse) VisitGetSlotsResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type SubmitTransactionRequestObject struct {
+	Params SubmitTransactionParams
+	Body   *SubmitTransactionJSONRequestBody
+}
+
+type SubmitTransactionResponseObject interface {
+	VisitSubmitTransactionResponse(w http.ResponseWriter) error
+}
+
+type SubmitTransaction200JSONResponse SubmitTxResponse
+
+func (response SubmitTransaction200JSONResponse) VisitSubmitTransactionResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type SubmitTransaction400JSONResponse struct {
+	// Code Either specific error code in case of invalid request or http status code
+	Code float32 `json:"code"`
+
+	// Message Message describing error
+	Message string `json:"message"`
+}
+
+func (response SubmitTransaction400JSONResponse) VisitSubmitTransactionResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(400)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type SubmitTransaction500JSONResponse struct {
+	// Code Either specific error code in case of invalid request or http status code
+	Code *float32 `json:"code,omitempty"`
+
+	// Message Message describing error
+	Message *string `json:"message,omitempty"`
+}
+
+func (response SubmitTransaction500JSONResponse) VisitSubmitTransactionResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(500)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+// StrictServerInterface represents all server handlers.
+type StrictServerInterface interface {
+	// Returns a fee quoted in "wei" per gas
+	// (POST /commitments/v0/preconf_fee)
+	GetFee(ctx context.Context, request GetFeeRequestObject) (GetFeeResponseObject, error)
+	// Reserves blockspace for a slot
+	// (POST /commitments/v0/reserve_blockspace)
+	ReserveBlockspace(ctx context.Context, request ReserveBlockspaceRequestObject) (ReserveBlockspaceResponseObject, error)
+	// Get a list of slots which are available in the current and next epoch
+	// (GET /commitments/v0/slots)
+	GetSlots(ctx context.Context, request GetSlotsRequestObject) (GetSlotsResponseObject, error)
+	// Used to submit transaction
+	// (POST /commitments/v0/submit_transaction)
+	SubmitTransaction(ctx context.Context, request SubmitTransactionRequestObject) (SubmitTransactionResponseObject, error)
+}
+
+type StrictHandlerFunc = strictnethttp.StrictHTTPHandlerFunc
+type StrictMiddlewareFunc = strictnethttp.StrictHTTPMiddlewareFunc
+
+type StrictHTTPServerOptions struct {
+	RequestErrorHandlerFunc  func(w http.ResponseWriter, r *http.Request, err error)
+	ResponseErrorHandlerFunc func(w http.ResponseWriter, r *http.Request, err error)
+}
+
+func NewStrictHandler(ssi StrictServerInterface, middlewares []StrictMiddlewareFunc) ServerInterface {
+	return &strictHandler{ssi: ssi, middlewares: middlewares, options: StrictHTTPServerOptions{
+		RequestErrorHandlerFunc: func(w http.ResponseWriter, r *http.Request, err error) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		},
+		ResponseErrorHandlerFunc: func(w http.ResponseWriter, r *http.Request, err error) {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		},
+	}}
+}
+
+func NewStrictHandlerWithOptions(ssi StrictServerInterface, middlewares []StrictMiddlewareFunc, options StrictHTTPServerOptions) ServerInterface {
+	return &strictHandler{ssi: ssi, middlewares: middlewares, options: options}
+}
+
+type strictHandler struct {
+	ssi         StrictServerInterface
+	middlewares []StrictMiddlewareFunc
+	options     StrictHTTPServerOptions
+}
+
+// GetFee operation middleware
+func (sh *strictHandler) GetFee(w http.ResponseWriter, r *http.Request) {
+	var request GetFeeRequestObject
+
+	var body GetFeeJSONRequestBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		sh.options.RequestErrorHandlerFunc(w, r, fmt.Errorf("can't decode JSON body: %w", err))
+		return
+	}
+	request.Body = &body
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, request interface{}) (interface{}, error) {
+		return sh.ssi.GetFee(ctx, request.(GetFeeRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "GetFee")
+	}
+
+	response, err := handler(r.Context(), w, r, request)
+
+	if err != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, err)
+	} else if validResponse, ok := response.(GetFeeResponseObject); ok {
+		if err := validResponse.VisitGetFeeResponse(w); err != nil {
+			sh.options.ResponseErrorHandlerFunc(w, r, err)
+		}
+	} else if response != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// ReserveBlockspace operation middleware
+func (sh *strictHandler) ReserveBlockspace(w http.ResponseWriter, r *http.Request, params ReserveBlockspaceParams) {
+	var request ReserveBlockspaceRequestObject
+
+	request.Params = params
+
+	var body ReserveBlockspaceJSONRequestBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		sh.options.RequestErrorHandlerFunc(w, r, fmt.Errorf("can't decode JSON body: %w", err))
+		return
+	}
+	request.Body = &body
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, request interface{}) (interface{}, error) {
+		return sh.ssi.ReserveBlockspace(ctx, request.(ReserveBlockspaceRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "ReserveBlockspace")
+	}
+
+	response, err := handler(r.Context(), w, r, request)
+
+	if err != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, err)
+	} else if validResponse, ok := response.(ReserveBlockspaceResponseObject); ok {
+		if err := validResponse.VisitReserveBlockspaceResponse(w); err != nil {
+			sh.options.ResponseErrorHandlerFunc(w, r, err)
+		}
+	} else if response != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// GetSlots operation middleware
+func (sh *strictHandler) GetSlots(w http.ResponseWriter, r *http.Request) {
+	var request GetSlotsRequestObject
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, request interface{}) (interface{}, error) {
+		return sh.ssi.GetSlots(ctx, request.(GetSlotsRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "GetSlots")
+	}
+
+	response, err := handler(r.Context(), w, r, request)
+
+	if err != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, err)
+	} else if validResponse, ok := response.(GetSlotsResponseObject); ok {
+		if err := validResponse.VisitGetSlotsResponse(w); err != nil {
+			sh.options.ResponseErrorHandlerFunc(w, r, err)
+		}
+	} else if response != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// SubmitTransaction operation middleware
+func (sh *strictHandler) SubmitTransaction(w http.ResponseWriter, r *http.Request, params SubmitTransactionParams) {
+	var request SubmitTransactionRequestObject
+
+	request.Params = params
+
+	var body SubmitTransactionJSONRequestBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		sh.options.RequestErrorHandlerFunc(w, r, fmt.Errorf("can't decode JSON body: %w", err))
+		return
+	}
+	request.Body = &body
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, request interface{}) (interface{}, error) {
+		return sh.ssi.SubmitTransaction(ctx, request.(SubmitTransactionRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "SubmitTransaction")
+	}
+
+	response, err := handler(r.Context(), w, r, request)
+
+	if err != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, err)
+	} else if validResponse, ok := response.(SubmitTransactionResponseObject); ok {
+		if err := validResponse.VisitSubmitTransactionResponse(w); err != nil {
+			sh.options.ResponseErrorHandlerFunc(w, r, err)
+		}
+	} else if response != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, fmt.Errorf("unexpected response type: %T", response))
+	}
+}
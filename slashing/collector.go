@@ -0,0 +1,93 @@
+package slashing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// SlashingSubmitter files a dispute for broken commitment, e.g. by sending
+// evidence to a dispute contract or to the team.
+type SlashingSubmitter interface {
+	SubmitEvidence(ctx context.Context, ev *Evidence) error
+}
+
+// Collector persists evidence and passes it to the submitter. Evidence is
+// saved before submission, so it isn't lost, if submission fails.
+type Collector struct {
+	l         log.Logger
+	store     Store
+	submitter SlashingSubmitter
+}
+
+// NewCollector creates collector. Nil submitter only persists evidence.
+func NewCollector(l log.Logger, store Store, submitter SlashingSubmitter) *Collector {
+	return &Collector{
+		l:         l,
+		store:     store,
+		submitter: submitter,
+	}
+}
+
+// Collect saves evidence and submits it.
+func (c *Collector) Collect(ctx context.Context, ev *Evidence) error {
+	if err := c.Save(ev); err != nil {
+		return err
+	}
+	return c.Submit(ctx, ev)
+}
+
+// Save fills in gateway address and collection time and persists evidence
+// without submitting it. It's submitted with [Collector.Submit] or
+// [Collector.RetryPending] later.
+func (c *Collector) Save(ev *Evidence) error {
+	if ev.CollectedAt.IsZero() {
+		ev.CollectedAt = time.Now()
+	}
+	if ev.Gateway == nil {
+		if err := ev.RecoverGateway(); err != nil {
+			c.l.Warn("Evidence has invalid gateway signature", "id", ev.RequestId, "err", err)
+		}
+	}
+	if err := c.store.Save(ev); err != nil {
+		return fmt.Errorf("failed to persist evidence: %w", err)
+	}
+	c.l.Info("Collected slashing evidence", "id", ev.RequestId, "kind", ev.Kind, "slot", ev.TargetSlot, "gateway", ev.Gateway)
+	return nil
+}
+
+// RetryPending submits all stored evidence, which wasn't submitted yet.
+func (c *Collector) RetryPending(ctx context.Context) error {
+	evidence, err := c.store.List()
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, ev := range evidence {
+		if ev.Submitted {
+			continue
+		}
+		if err := c.Submit(ctx, ev); err != nil {
+			errs = append(errs, fmt.Errorf("request %v: %w", ev.RequestId, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Submit passes saved evidence to the submitter and marks it submitted.
+func (c *Collector) Submit(ctx context.Context, ev *Evidence) error {
+	if c.submitter == nil {
+		return nil
+	}
+	if err := c.submitter.SubmitEvidence(ctx, ev); err != nil {
+		return fmt.Errorf("failed to submit evidence: %w", err)
+	}
+	ev.Submitted = true
+	if err := c.store.Save(ev); err != nil {
+		return fmt.Errorf("failed to persist submitted evidence: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+// Package slashing collects evidence of commitments broken by the gateway, so
+// they can be disputed.
+package slashing
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/risechain/luban-api/beacon"
+	luban "github.com/risechain/luban-api/types"
+)
+
+// Kind is the way commitment was broken.
+type Kind string
+
+const (
+	// SubmissionFailed means gateway failed to accept tx for the reservation
+	SubmissionFailed Kind = "submission_failed"
+	// NotIncluded means target slot has a block without tx
+	NotIncluded Kind = "not_included"
+	// IncludedLate means tx was included in other block, than the target
+	// slot one
+	IncludedLate Kind = "included_late"
+	// SlotMissed means target slot has no block
+	SlotMissed Kind = "slot_missed"
+)
+
+// Evidence is a bundle of everything needed to prove that commitment was
+// broken.
+type Evidence struct {
+	Kind Kind `json:"kind"`
+	// Reason is human readable detail, e.g. error returned by the gateway
+	Reason string `json:"reason,omitempty"`
+
+	RequestId  uuid.UUID                      `json:"request_id"`
+	TargetSlot uint64                         `json:"target_slot"`
+	Request    luban.ReserveBlockSpaceRequest `json:"request"`
+	// RequestSignature is our x-luban-signature of the reservation
+	RequestSignature string `json:"request_signature"`
+	// Receipt of the reservation, which carries gateway signature if the
+	// gateway signed it
+	ReservationReceipt luban.ReserveBlockSpaceResponse `json:"reservation_receipt"`
+
+	Tx *types.Transaction `json:"tx"`
+	// Commitment is nil, if gateway didn't return one
+	Commitment *luban.Commitment `json:"commitment,omitempty"`
+	// Gateway is the signer of the commitment or the reservation receipt,
+	// if any of them is signed
+	Gateway *common.Address `json:"gateway,omitempty"`
+
+	// BeaconBlock and ExecutionBlock are blocks of the target slot. They are
+	// nil if the slot was missed or hasn't happened yet.
+	BeaconBlock    *beacon.Block  `json:"beacon_block,omitempty"`
+	ExecutionBlock *types.Header  `json:"execution_block,omitempty"`
+	TxReceipt      *types.Receipt `json:"tx_receipt,omitempty"`
+	CollectedAt    time.Time      `json:"collected_at"`
+	// Submitted is set once evidence was passed to [SlashingSubmitter]
+	Submitted bool `json:"submitted"`
+}
+
+// RecoverGateway sets Gateway from commitment or reservation receipt
+// signature, whichever is present.
+func (e *Evidence) RecoverGateway() error {
+	var (
+		gateway common.Address
+		err     error
+	)
+	switch {
+	case e.Commitment != nil:
+		gateway, err = e.Commitment.Signer(e.RequestId, e.Tx)
+	case e.ReservationReceipt.IsSigned():
+		gateway, err = e.ReservationReceipt.Signer(&e.Request)
+	default:
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to recover gateway: %w", err)
+	}
+	e.Gateway = &gateway
+	return nil
+}
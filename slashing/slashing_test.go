@@ -0,0 +1,173 @@
+package slashing
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/ethereum-optimism/optimism/op-service/testlog"
+
+	"github.com/risechain/luban-api/beacon"
+	luban "github.com/risechain/luban-api/types"
+)
+
+type recordingSubmitter struct {
+	fail      bool
+	submitted []uuid.UUID
+}
+
+func (s *recordingSubmitter) SubmitEvidence(ctx context.Context, ev *Evidence) error {
+	if s.fail {
+		return errors.New("dispute contract unavailable")
+	}
+	s.submitted = append(s.submitted, ev.RequestId)
+	return nil
+}
+
+func newTestEvidence(t *testing.T, gatewayKey *ecdsa.PrivateKey, collectedAt time.Time) *Evidence {
+	key, _ := crypto.GenerateKey()
+	chainId := big.NewInt(7028081469)
+	tx := types.MustSignNewTx(key, types.LatestSignerForChainID(chainId), &types.DynamicFeeTx{
+		ChainID:   chainId,
+		GasFeeCap: big.NewInt(1),
+		Gas:       21000,
+	})
+	id := uuid.New()
+	sig, err := crypto.Sign(luban.SubmitTxDigest(id, tx).Bytes(), gatewayKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	commitment, err := luban.NewCommitment(sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &Evidence{
+		Kind:       NotIncluded,
+		RequestId:  id,
+		TargetSlot: 10,
+		Request: luban.ReserveBlockSpaceRequest{
+			GasLimit:   21000,
+			TargetSlot: 10,
+			Deposit:    hexutil.U256{1},
+		},
+		Tx:          tx,
+		Commitment:  &commitment,
+		BeaconBlock: &beacon.Block{Slot: 10, BlockNumber: 100, BlockHash: common.Hash{1}},
+		ExecutionBlock: &types.Header{
+			Number:     big.NewInt(100),
+			Difficulty: big.NewInt(0),
+			BaseFee:    big.NewInt(1),
+		},
+		CollectedAt: collectedAt,
+	}
+}
+
+func TestFileStore(t *testing.T) {
+	store, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	gatewayKey, _ := crypto.GenerateKey()
+	now := time.Now().UTC().Truncate(time.Second)
+	second := newTestEvidence(t, gatewayKey, now.Add(time.Second))
+	first := newTestEvidence(t, gatewayKey, now)
+	for _, ev := range []*Evidence{second, first} {
+		if err := store.Save(ev); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	loaded, err := store.Load(first.RequestId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Tx.Hash() != first.Tx.Hash() || *loaded.Commitment != *first.Commitment || loaded.Request != first.Request {
+		t.Fatalf("Evidence changed in store: %+v", loaded)
+	}
+	if loaded.ExecutionBlock.Hash() != first.ExecutionBlock.Hash() || *loaded.BeaconBlock != *first.BeaconBlock {
+		t.Fatal("Evidence blocks changed in store")
+	}
+
+	all, err := store.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 2 || all[0].RequestId != first.RequestId || all[1].RequestId != second.RequestId {
+		t.Fatal("Wrong evidence listed")
+	}
+
+	if _, err := store.Load(uuid.New()); !errors.Is(err, ErrEvidenceNotFound) {
+		t.Fatalf("Expected %v, have %v", ErrEvidenceNotFound, err)
+	}
+}
+
+func TestRecoverGatewayFromReceipt(t *testing.T) {
+	gatewayKey, _ := crypto.GenerateKey()
+	ev := newTestEvidence(t, gatewayKey, time.Now())
+	ev.Kind = SubmissionFailed
+	ev.Commitment = nil
+
+	sig, err := crypto.Sign(luban.ReservationDigest(ev.RequestId, &ev.Request).Bytes(), gatewayKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ev.ReservationReceipt = luban.ReserveBlockSpaceResponse{RequestId: ev.RequestId, Signature: sig}
+	if err := ev.RecoverGateway(); err != nil {
+		t.Fatal(err)
+	}
+	if want := crypto.PubkeyToAddress(gatewayKey.PublicKey); ev.Gateway == nil || *ev.Gateway != want {
+		t.Fatalf("Wrong gateway. Have %v, want %v", ev.Gateway, want)
+	}
+}
+
+func TestCollector(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	submitter := &recordingSubmitter{fail: true}
+	collector := NewCollector(testlog.Logger(t, log.LevelDebug), store, submitter)
+
+	gatewayKey, _ := crypto.GenerateKey()
+	ev := newTestEvidence(t, gatewayKey, time.Time{})
+	if err := collector.Collect(ctx, ev); err == nil {
+		t.Fatal("Submission failure wasn't returned")
+	}
+	saved, err := store.Load(ev.RequestId)
+	if err != nil {
+		t.Fatalf("Evidence wasn't saved before submission: %v", err)
+	}
+	if saved.Submitted || saved.CollectedAt.IsZero() {
+		t.Fatalf("Wrong saved evidence: %+v", saved)
+	}
+	if saved.Gateway == nil || *saved.Gateway != crypto.PubkeyToAddress(gatewayKey.PublicKey) {
+		t.Fatalf("Wrong gateway recovered: %v", saved.Gateway)
+	}
+
+	submitter.fail = false
+	if err := collector.RetryPending(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := collector.RetryPending(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(submitter.submitted) != 1 || submitter.submitted[0] != ev.RequestId {
+		t.Fatalf("Evidence should be submitted once, have %v", submitter.submitted)
+	}
+	if saved, _ := store.Load(ev.RequestId); !saved.Submitted {
+		t.Fatal("Evidence isn't marked submitted")
+	}
+}
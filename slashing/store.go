@@ -0,0 +1,116 @@
+package slashing
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// ErrEvidenceNotFound is returned by [Store.Load] for unknown request id.
+var ErrEvidenceNotFound = errors.New("evidence not found")
+
+// Store persists evidence by request id.
+type Store interface {
+	// Save stores evidence, replacing previous evidence of the same request.
+	Save(ev *Evidence) error
+	Load(reqId uuid.UUID) (*Evidence, error)
+	// List returns all stored evidence ordered by collection time.
+	List() ([]*Evidence, error)
+}
+
+// FileStore keeps every evidence as `<request id>.json` in a directory.
+type FileStore struct {
+	dir string
+}
+
+var _ Store = (*FileStore)(nil)
+
+// NewFileStore creates store in dir, creating the directory if needed.
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create evidence dir: %w", err)
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+func (s *FileStore) path(reqId uuid.UUID) string {
+	return filepath.Join(s.dir, reqId.String()+".json")
+}
+
+func (s *FileStore) Save(ev *Evidence) error {
+	data, err := json.MarshalIndent(ev, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode evidence: %w", err)
+	}
+
+	// Write to temporary file first, so evidence is never half written
+	tmp, err := os.CreateTemp(s.dir, ".evidence-*")
+	if err != nil {
+		return fmt.Errorf("failed to create evidence file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write evidence: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write evidence: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write evidence: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path(ev.RequestId)); err != nil {
+		return fmt.Errorf("failed to save evidence: %w", err)
+	}
+	return nil
+}
+
+func (s *FileStore) Load(reqId uuid.UUID) (*Evidence, error) {
+	data, err := os.ReadFile(s.path(reqId))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %v", ErrEvidenceNotFound, reqId)
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read evidence: %w", err)
+	}
+
+	var ev Evidence
+	if err := json.Unmarshal(data, &ev); err != nil {
+		return nil, fmt.Errorf("failed to decode evidence %v: %w", reqId, err)
+	}
+	return &ev, nil
+}
+
+func (s *FileStore) List() ([]*Evidence, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list evidence: %w", err)
+	}
+
+	var evidence []*Evidence
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".json")
+		if !ok || entry.IsDir() {
+			continue
+		}
+		reqId, err := uuid.Parse(name)
+		if err != nil {
+			continue
+		}
+		ev, err := s.Load(reqId)
+		if err != nil {
+			return nil, err
+		}
+		evidence = append(evidence, ev)
+	}
+	sort.Slice(evidence, func(i, j int) bool {
+		return evidence[i].CollectedAt.Before(evidence[j].CollectedAt)
+	})
+	return evidence, nil
+}
@@ -0,0 +1,174 @@
+package txmgr
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/consensus/misc/eip1559"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// ErrUnknownChain is returned, when chain config wasn't passed with
+// [WithChainConfig] and chain id isn't one of known networks.
+var ErrUnknownChain = errors.New("unknown chain")
+
+// BlobConfig is a blob schedule of a fork.
+type BlobConfig struct {
+	// Target and Max are numbers of blobs per block
+	Target uint64
+	Max    uint64
+	// UpdateFraction controls maximum rate of change of blob base fee
+	UpdateFraction uint64
+}
+
+var (
+	// CancunBlobConfig is the blob schedule of EIP-4844
+	CancunBlobConfig = BlobConfig{Target: 3, Max: 6, UpdateFraction: 3338477}
+	// PragueBlobConfig is the blob schedule of EIP-7691
+	PragueBlobConfig = BlobConfig{Target: 6, Max: 9, UpdateFraction: 5007716}
+)
+
+// BlobSchedule holds blob configs of forks. Devnets, which run with other
+// values than public networks, pass theirs with [WithBlobSchedule].
+type BlobSchedule struct {
+	Cancun BlobConfig
+	Prague BlobConfig
+}
+
+// DefaultBlobSchedule returns blob schedule of mainnet and public testnets.
+func DefaultBlobSchedule() BlobSchedule {
+	return BlobSchedule{
+		Cancun: CancunBlobConfig,
+		Prague: PragueBlobConfig,
+	}
+}
+
+// Validate checks, that every fork has max blobs at least the target and
+// positive update fraction.
+func (s BlobSchedule) Validate() error {
+	for _, fork := range []struct {
+		name   string
+		config BlobConfig
+	}{{"Cancun", s.Cancun}, {"Prague", s.Prague}} {
+		if c := fork.config; c.Max < c.Target || c.UpdateFraction == 0 {
+			return fmt.Errorf("invalid %s blob config %+v", fork.name, c)
+		}
+	}
+	return nil
+}
+
+// At returns blob config active at block num and time, or nil before Cancun.
+func (s BlobSchedule) At(config *params.ChainConfig, num *big.Int, time uint64) *BlobConfig {
+	switch {
+	case config.IsPrague(num, time):
+		return &s.Prague
+	case config.IsCancun(num, time):
+		return &s.Cancun
+	}
+	return nil
+}
+
+// BlobConfigAt returns blob config of [DefaultBlobSchedule] active at block
+// num and time, or nil before Cancun.
+func BlobConfigAt(config *params.ChainConfig, num *big.Int, time uint64) *BlobConfig {
+	return DefaultBlobSchedule().At(config, num, time)
+}
+
+// ExcessBlobGas returns excess blob gas of a block following parent.
+func (c *BlobConfig) ExcessBlobGas(parent *types.Header) uint64 {
+	var excess, used uint64
+	if parent.ExcessBlobGas != nil {
+		excess = *parent.ExcessBlobGas
+	}
+	if parent.BlobGasUsed != nil {
+		used = *parent.BlobGasUsed
+	}
+	target := c.Target * params.BlobTxBlobGasPerBlob
+	if excess+used < target {
+		return 0
+	}
+	return excess + used - target
+}
+
+// BlobFee returns blob base fee of a block with excess blob gas.
+func (c *BlobConfig) BlobFee(excessBlobGas uint64) *big.Int {
+	return fakeExponential(
+		big.NewInt(params.BlobTxMinBlobGasprice),
+		new(big.Int).SetUint64(excessBlobGas),
+		new(big.Int).SetUint64(c.UpdateFraction),
+	)
+}
+
+// fakeExponential approximates factor * e ** (numerator / denominator) using
+// Taylor expansion.
+//
+// Copied from go-ethereum/consensus/misc/eip4844, which has update fraction
+// hard-coded.
+func fakeExponential(factor, numerator, denominator *big.Int) *big.Int {
+	var (
+		output = new(big.Int)
+		accum  = new(big.Int).Mul(factor, denominator)
+	)
+	for i := 1; accum.Sign() > 0; i++ {
+		output.Add(output, accum)
+
+		accum.Mul(accum, numerator)
+		accum.Div(accum, denominator)
+		accum.Div(accum, big.NewInt(int64(i)))
+	}
+	return output.Div(output, denominator)
+}
+
+// Prague activation times, which go-ethereum doesn't know yet
+var pragueTimes = map[uint64]uint64{
+	params.MainnetChainConfig.ChainID.Uint64(): 1746612311,
+	params.SepoliaChainConfig.ChainID.Uint64(): 1741159776,
+	params.HoleskyChainConfig.ChainID.Uint64(): 1740434112,
+}
+
+// ChainConfigByID returns config of a known network: mainnet, Sepolia or
+// Holesky. Devnets pass their config with [WithChainConfig].
+func ChainConfigByID(chainId *big.Int) (*params.ChainConfig, error) {
+	var config params.ChainConfig
+	switch {
+	case chainId == nil:
+		return nil, fmt.Errorf("%w: no chain id", ErrUnknownChain)
+	case chainId.Cmp(params.MainnetChainConfig.ChainID) == 0:
+		config = *params.MainnetChainConfig
+	case chainId.Cmp(params.SepoliaChainConfig.ChainID) == 0:
+		config = *params.SepoliaChainConfig
+	case chainId.Cmp(params.HoleskyChainConfig.ChainID) == 0:
+		config = *params.HoleskyChainConfig
+	default:
+		return nil, fmt.Errorf("%w: %v", ErrUnknownChain, chainId)
+	}
+	if config.PragueTime == nil {
+		prague := pragueTimes[chainId.Uint64()]
+		config.PragueTime = &prague
+	}
+	return &config, nil
+}
+
+// chainConfig returns config passed with [WithChainConfig] or the one of
+// ChainID from txmgr config.
+func (m *PreconfTxMgr) chainConfig() (*params.ChainConfig, error) {
+	if m.chain != nil {
+		return m.chain, nil
+	}
+	return ChainConfigByID(m.cfg.ChainID)
+}
+
+// calcBaseFees returns base fee and blob base fee of a block following
+// parent at time. Blob base fee is nil before Cancun.
+func calcBaseFees(config *params.ChainConfig, schedule BlobSchedule, parent *types.Header, time uint64) (*big.Int, *big.Int) {
+	baseFee := eip1559.CalcBaseFee(config, parent, time)
+
+	num := new(big.Int).Add(parent.Number, big.NewInt(1))
+	blobConfig := schedule.At(config, num, time)
+	if blobConfig == nil {
+		return baseFee, nil
+	}
+	return baseFee, blobConfig.BlobFee(blobConfig.ExcessBlobGas(parent))
+}
@@ -0,0 +1,114 @@
+package txmgr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/risechain/luban-api/client"
+)
+
+// ErrNoSlotsAvailable is returned, when gateway offers no slot with enough
+// gas and blobs for the transaction.
+var ErrNoSlotsAvailable = errors.New("No slots available for transaction")
+
+// SendPath is the way transaction reached the chain.
+type SendPath int
+
+const (
+	// PathPreconf means tx was preconfirmed by the gateway
+	PathPreconf SendPath = iota
+	// PathMempool means tx was broadcast to the public mempool
+	PathMempool
+)
+
+func (p SendPath) String() string {
+	switch p {
+	case PathPreconf:
+		return "preconf"
+	case PathMempool:
+		return "mempool"
+	}
+	return fmt.Sprintf("SendPath(%d)", int(p))
+}
+
+// SendResult is the result of [PreconfTxMgr.SendWithResult].
+type SendResult struct {
+	Receipt *types.Receipt
+	Path    SendPath
+	// Reservation is the last blockspace reservation made for tx, nil if
+	// there was none
+	Reservation *client.Reservation
+	// Outcome of the preconf. It's set only for PathPreconf.
+	Outcome InclusionOutcome
+	// FallbackReason tells why tx was broadcast to the mempool
+	FallbackReason string
+}
+
+// FallbackPolicy decides, when tx manager gives up on preconfirmation and
+// broadcasts the same signed tx to the public mempool. Zero policy never
+// falls back. Policy with MaxFailures or Deadline also falls back, when
+// [RetryPolicy] runs out first.
+type FallbackPolicy struct {
+	// MaxFailures is the number of failed attempts to get slots, price or
+	// reserve blockspace or submit tx, after which tx falls back. Zero means
+	// failed attempts are retried as long as [RetryPolicy] allows.
+	MaxFailures int
+	// Deadline is how long preconfirmation may take since the start of
+	// sending, before tx falls back. Zero means no deadline.
+	Deadline time.Duration
+	// OnNoSlots makes tx fall back, when no slot fits it.
+	OnNoSlots bool
+	// OnNotHonored makes tx fall back, when target slot passed without it.
+	OnNotHonored bool
+}
+
+func (p *FallbackPolicy) failuresExhausted(failures int) bool {
+	return p.MaxFailures != 0 && failures >= p.MaxFailures
+}
+
+// bounded reports whether failed attempts eventually fall back.
+func (p *FallbackPolicy) bounded() bool {
+	return p.MaxFailures != 0 || p.Deadline != 0
+}
+
+func (p *FallbackPolicy) deadlinePassed(start time.Time) bool {
+	return p.Deadline != 0 && time.Since(start) >= p.Deadline
+}
+
+// sendToMempool broadcasts tx and waits for its receipt.
+func (m *PreconfTxMgr) sendToMempool(ctx context.Context, tx *types.Transaction, res *SendResult, reason string) (*SendResult, error) {
+	l := m.l.New("tx", tx.Hash(), "nonce", tx.Nonce())
+	l.Warn("Falling back to public mempool", "reason", reason)
+	res.Path = PathMempool
+	res.FallbackReason = reason
+
+	// Gateway may have already broadcast it
+	if err := m.backend.SendTransaction(ctx, tx); err != nil && !strings.Contains(err.Error(), "already known") {
+		return nil, fmt.Errorf("Broadcasting tx to mempool failed: %w", err)
+	}
+
+	for {
+		receipt, err := m.backend.TransactionReceipt(ctx, tx.Hash())
+		if receipt != nil && err == nil {
+			if m.confirmed(ctx, receipt) {
+				l.Info("Transaction confirmed from mempool", "block", receipt.BlockNumber)
+				res.Receipt = receipt
+				return res, nil
+			}
+		} else if err != nil && !errors.Is(err, ethereum.NotFound) {
+			l.Warn("Failed to query receipt", "err", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("Waiting for mempool tx inclusion failed: %w", ctx.Err())
+		case <-time.After(m.receiptInterval()):
+		}
+	}
+}
@@ -0,0 +1,301 @@
+package txmgr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	u256 "github.com/holiman/uint256"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+
+	"github.com/risechain/luban-api/beacon"
+)
+
+// DefaultFeeSafetyMultiplier is applied to fee caps projected to the target
+// slot, unless [WithFeeSafetyMultiplier] is passed.
+const DefaultFeeSafetyMultiplier = 1.0
+
+// ErrFeeLimit is returned, when fee caps projected to the target slot exceed
+// FeeLimitMultiplier of txmgr config times caps suggested for the next
+// block.
+var ErrFeeLimit = errors.New("fee cap over the limit")
+
+// ErrBlobsBeforeCancun is returned for blob transactions on chains without
+// Cancun.
+var ErrBlobsBeforeCancun = errors.New("blob transactions aren't supported before Cancun")
+
+// FeePolicy decides tip and fee caps of crafted tx. Zero policy suggests tip
+// with the backend and doubles base fees, as op-service txmgr does.
+// Minimums of txmgr config (MinTipCap, MinBaseFee and MinBlobTxFee) are
+// always enforced.
+type FeePolicy struct {
+	// Tip is a fixed priority fee. If nil, tip is suggested by the backend.
+	Tip *big.Int
+	// MaxTipCap bounds the tip. Nil means no bound.
+	MaxTipCap *big.Int
+	// MinFeeCap and MaxFeeCap bound gas fee cap. Nil means no bound.
+	MinFeeCap *big.Int
+	MaxFeeCap *big.Int
+	// BaseFeeMultiplier is applied to base fee to get fee cap. Zero means 2.
+	BaseFeeMultiplier uint64
+	// BlobFeeCapMultiplier is applied to blob base fee to get blob fee cap.
+	// Zero means 2.
+	BlobFeeCapMultiplier uint64
+	// MaxBlobFeeCap bounds blob fee cap. Nil means no bound.
+	MaxBlobFeeCap *big.Int
+}
+
+func multiplierOrDefault(multiplier uint64) *big.Int {
+	if multiplier == 0 {
+		return big.NewInt(2)
+	}
+	return new(big.Int).SetUint64(multiplier)
+}
+
+// clamp bounds x by min and max, which may be nil.
+func clamp(x, min, max *big.Int) *big.Int {
+	if min != nil && x.Cmp(min) < 0 {
+		return new(big.Int).Set(min)
+	}
+	if max != nil && x.Cmp(max) > 0 {
+		return new(big.Int).Set(max)
+	}
+	return x
+}
+
+// feeCap returns tip plus base fee times multiplier within the bounds.
+func (p *FeePolicy) feeCap(baseFee, tip *big.Int) *big.Int {
+	feeCap := new(big.Int).Mul(baseFee, multiplierOrDefault(p.BaseFeeMultiplier))
+	return clamp(feeCap.Add(feeCap, tip), p.MinFeeCap, p.MaxFeeCap)
+}
+
+// caps returns tip and fee cap within the bounds. Tip over fee cap bounded
+// by MaxFeeCap is lowered to it, which fails, if it goes below minTip.
+func (p *FeePolicy) caps(baseFee, tip, minTip *big.Int) (*big.Int, *big.Int, error) {
+	feeCap := p.feeCap(baseFee, tip)
+	if tip.Cmp(feeCap) <= 0 {
+		return tip, feeCap, nil
+	}
+	if minTip != nil && feeCap.Cmp(minTip) < 0 {
+		return nil, nil, fmt.Errorf("fee cap %v is below min tip cap %v", feeCap, minTip)
+	}
+	return new(big.Int).Set(feeCap), feeCap, nil
+}
+
+// blobFeeCap returns blob base fee times multiplier within the bounds.
+func (p *FeePolicy) blobFeeCap(blobFee, minBlobFee *big.Int) *big.Int {
+	blobFeeCap := new(big.Int).Mul(blobFee, multiplierOrDefault(p.BlobFeeCapMultiplier))
+	return clamp(blobFeeCap, minBlobFee, p.MaxBlobFeeCap)
+}
+
+// suggestTip returns fixed tip of the fee policy or the one suggested by the
+// backend, within MinTipCap of txmgr config and MaxTipCap of the policy.
+func (m *PreconfTxMgr) suggestTip(ctx context.Context) (*big.Int, error) {
+	tip := m.feePolicy.Tip
+	if tip == nil {
+		cCtx, cancel := context.WithTimeout(ctx, m.cfg.NetworkTimeout)
+		defer cancel()
+		var err error
+		if tip, err = m.backend.SuggestGasTipCap(cCtx); err != nil {
+			return nil, fmt.Errorf("failed to suggest tip: %w", err)
+		}
+	}
+	return clamp(tip, m.cfg.MinTipCap.Load(), m.feePolicy.MaxTipCap), nil
+}
+
+// checkFeeLimit checks that raised cap isn't over FeeLimitMultiplier times
+// the suggested one, unless it's under FeeLimitThreshold, as op-service
+// txmgr does for fee bumps.
+func (m *PreconfTxMgr) checkFeeLimit(name string, suggested, raised *big.Int) error {
+	multiplier := m.cfg.FeeLimitMultiplier.Load()
+	if multiplier == 0 {
+		return nil
+	}
+	if threshold := m.cfg.FeeLimitThreshold.Load(); threshold != nil && threshold.Cmp(raised) > 0 {
+		return nil
+	}
+	if limit := new(big.Int).Mul(suggested, new(big.Int).SetUint64(multiplier)); raised.Cmp(limit) > 0 {
+		return fmt.Errorf("%w: %s cap %v is over %dx multiple of the suggested value", ErrFeeLimit, name, raised, multiplier)
+	}
+	return nil
+}
+
+// slotClock returns slot clock passed with [WithSlotClock], or the one
+// derived from beacon node genesis and spec, which is fetched once.
+func (m *PreconfTxMgr) slotClock(ctx context.Context) (*beacon.SlotClock, error) {
+	if m.slots != nil {
+		return m.slots, nil
+	}
+
+	m.beaconSlotsLock.Lock()
+	defer m.beaconSlotsLock.Unlock()
+	if m.beaconSlots != nil {
+		return m.beaconSlots, nil
+	}
+	cCtx, cancel := context.WithTimeout(ctx, m.cfg.NetworkTimeout)
+	defer cancel()
+	clock, err := beacon.NewSlotClockFromBeacon(cCtx, m.beacon, nil)
+	if err != nil {
+		return nil, err
+	}
+	m.beaconSlots = clock
+	return clock, nil
+}
+
+// blockTimes returns timestamps of all blocks after parent up to and
+// including the one of slot, assuming no slot is missed. There is at least
+// one block.
+func blockTimes(clock *beacon.SlotClock, parent *types.Header, slot uint64) []uint64 {
+	parentSlot := clock.SlotAt(time.Unix(int64(parent.Time), 0))
+	times := []uint64{uint64(clock.SlotStart(parentSlot + 1).Unix())}
+	for s := parentSlot + 2; s <= slot; s++ {
+		times = append(times, uint64(clock.SlotStart(s).Unix()))
+	}
+	return times
+}
+
+// projectFees returns the highest base fee and blob base fee, which the last
+// of blocks at times can have, if every block after parent is full. Blob
+// base fee is nil, if Cancun isn't active at the last block.
+func projectFees(config *params.ChainConfig, schedule BlobSchedule, parent *types.Header, times []uint64) (*big.Int, *big.Int) {
+	num := new(big.Int).Add(parent.Number, big.NewInt(1))
+	baseFee, _ := calcBaseFees(config, schedule, parent, times[0])
+
+	var excess uint64
+	blobConfig := schedule.At(config, num, times[0])
+	if blobConfig != nil {
+		excess = blobConfig.ExcessBlobGas(parent)
+	}
+
+	for _, t := range times[1:] {
+		// Full block raises base fee by (elasticity - 1) / denominator
+		delta := new(big.Int).Mul(baseFee, new(big.Int).SetUint64(config.ElasticityMultiplier()-1))
+		delta.Div(delta, new(big.Int).SetUint64(config.BaseFeeChangeDenominator(t)))
+		baseFee.Add(baseFee, bigMax(delta, big.NewInt(1)))
+
+		// Block with max blobs raises excess by max - target of the next fork
+		num.Add(num, big.NewInt(1))
+		prev := blobConfig
+		blobConfig = schedule.At(config, num, t)
+		if prev == nil || blobConfig == nil {
+			continue
+		}
+		if used, target := prev.Max*params.BlobTxBlobGasPerBlob, blobConfig.Target*params.BlobTxBlobGasPerBlob; excess+used > target {
+			excess = excess + used - target
+		} else {
+			excess = 0
+		}
+	}
+
+	if blobConfig == nil {
+		return baseFee, nil
+	}
+	return baseFee, blobConfig.BlobFee(excess)
+}
+
+// slotFeeCaps returns base fee and blob base fee, which keep tx includable
+// at the target slot, even if all blocks till then are full.
+func (m *PreconfTxMgr) slotFeeCaps(ctx context.Context, slot uint64) (*big.Int, *big.Int, error) {
+	config, err := m.chainConfig()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get chain config: %w", err)
+	}
+	clock, err := m.slotClock(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get slot clock: %w", err)
+	}
+	parent, err := m.backend.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get latest header: %w", err)
+	}
+
+	baseFee, blobFee := projectFees(config, m.blobs, parent, blockTimes(clock, parent, slot))
+	baseFee = mulFloat(baseFee, m.feeMultiplier)
+	if blobFee != nil {
+		blobFee = mulFloat(blobFee, m.feeMultiplier)
+	}
+	return baseFee, blobFee, nil
+}
+
+// priceForSlot raises fee caps of not yet submitted tx to the ones projected
+// to the target slot. Tx is re-signed with the same nonce, if caps change.
+func (m *PreconfTxMgr) priceForSlot(ctx context.Context, tx *types.Transaction, slot uint64) (*types.Transaction, error) {
+	baseFee, blobFee, err := m.slotFeeCaps(ctx, slot)
+	if err != nil {
+		return nil, err
+	}
+	feeCap := new(big.Int).Add(baseFee, tx.GasTipCap())
+	feeCap = bigMax(clamp(feeCap, nil, m.feePolicy.MaxFeeCap), tx.GasFeeCap())
+	blobFeeCap := tx.BlobGasFeeCap()
+	if tx.Type() == types.BlobTxType && blobFee != nil {
+		blobFeeCap = bigMax(clamp(blobFee, nil, m.feePolicy.MaxBlobFeeCap), blobFeeCap)
+	}
+	if feeCap.Cmp(tx.GasFeeCap()) == 0 && (blobFeeCap == nil || blobFeeCap.Cmp(tx.BlobGasFeeCap()) == 0) {
+		return tx, nil
+	}
+	if err := m.checkFeeLimit("fee", tx.GasFeeCap(), feeCap); err != nil {
+		return nil, err
+	}
+	if blobFeeCap != nil {
+		if err := m.checkFeeLimit("blob fee", tx.BlobGasFeeCap(), blobFeeCap); err != nil {
+			return nil, err
+		}
+	}
+	m.l.Debug("Raising fee caps to target slot projection", "tx", tx.Hash(), "slot", slot, "feeCap", feeCap, "blobFeeCap", blobFeeCap)
+
+	var txMessage types.TxData
+	switch tx.Type() {
+	case types.DynamicFeeTxType:
+		txMessage = &types.DynamicFeeTx{
+			ChainID:    tx.ChainId(),
+			Nonce:      tx.Nonce(),
+			GasTipCap:  tx.GasTipCap(),
+			GasFeeCap:  feeCap,
+			Gas:        tx.Gas(),
+			To:         tx.To(),
+			Value:      tx.Value(),
+			Data:       tx.Data(),
+			AccessList: tx.AccessList(),
+		}
+	case types.BlobTxType:
+		if blobFee == nil {
+			return nil, ErrBlobsBeforeCancun
+		}
+		txMessage = &types.BlobTx{
+			ChainID:    u256.MustFromBig(tx.ChainId()),
+			Nonce:      tx.Nonce(),
+			GasTipCap:  u256.MustFromBig(tx.GasTipCap()),
+			GasFeeCap:  u256.MustFromBig(feeCap),
+			Gas:        tx.Gas(),
+			To:         *tx.To(),
+			Value:      u256.MustFromBig(tx.Value()),
+			Data:       tx.Data(),
+			AccessList: tx.AccessList(),
+			BlobFeeCap: u256.MustFromBig(blobFeeCap),
+			BlobHashes: tx.BlobHashes(),
+			Sidecar:    tx.BlobTxSidecar(),
+		}
+	default:
+		return nil, fmt.Errorf("unrecognized tx type: %d", tx.Type())
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, m.cfg.NetworkTimeout)
+	defer cancel()
+	return m.cfg.Signer(ctx, m.cfg.From, types.NewTx(txMessage))
+}
+
+func bigMax(x, y *big.Int) *big.Int {
+	if x.Cmp(y) >= 0 {
+		return x
+	}
+	return y
+}
+
+func mulFloat(x *big.Int, f float64) *big.Int {
+	res, _ := new(big.Float).Mul(new(big.Float).SetInt(x), big.NewFloat(f)).Int(nil)
+	return res
+}
@@ -0,0 +1,246 @@
+package txmgr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/risechain/luban-api/beacon"
+	"github.com/risechain/luban-api/client"
+	luban "github.com/risechain/luban-api/types"
+)
+
+const (
+	// DefaultInclusionTimeout bounds waiting for inclusion of preconfed tx
+	// after target slot passed, which covers default 10 confirmations
+	DefaultInclusionTimeout = 2 * time.Minute
+	// DefaultInclusionGrace is how long receipt is awaited after target slot
+	// passed, which covers execution client lagging behind beacon node.
+	DefaultInclusionGrace = 4 * time.Second
+)
+
+// InclusionOutcome tells, whether preconfed tx was included in the target
+// slot.
+type InclusionOutcome int
+
+const (
+	// IncludedOnTime means tx is in the execution payload of the target slot
+	IncludedOnTime InclusionOutcome = iota
+	// IncludedLate means target slot has a block, but tx was included in
+	// another one
+	IncludedLate
+	// SlotMissed means target slot has no block. Tx may still be included
+	// later.
+	SlotMissed
+	// NotIncluded means target slot has a block without tx, and tx wasn't
+	// included shortly after
+	NotIncluded
+)
+
+func (o InclusionOutcome) String() string {
+	switch o {
+	case IncludedOnTime:
+		return "included on time"
+	case IncludedLate:
+		return "included late"
+	case SlotMissed:
+		return "slot missed"
+	case NotIncluded:
+		return "not included"
+	}
+	return fmt.Sprintf("InclusionOutcome(%d)", int(o))
+}
+
+// InclusionReport describes how commitment was honored.
+type InclusionReport struct {
+	Outcome     InclusionOutcome
+	Reservation *client.Reservation
+	// Commitment is nil, if it was lost because response to the submission
+	// was lost and resubmission found tx already submitted
+	Commitment *luban.Commitment
+	Tx         *types.Transaction
+	// Block is the beacon block of the target slot, nil if slot was missed
+	Block *beacon.Block
+	// Receipt of tx, nil if it wasn't included
+	Receipt *types.Receipt
+}
+
+// ErrCommitmentNotHonored is returned, when target slot passed without
+// preconfed tx. Errors matching it are [*CommitmentNotHonoredError].
+var ErrCommitmentNotHonored = errors.New("commitment not honored")
+
+// CommitmentNotHonoredError describes commitment, which gateway didn't honor.
+type CommitmentNotHonoredError struct {
+	Slot      uint64
+	RequestId uuid.UUID
+	TxHash    common.Hash
+	// Outcome is either [SlotMissed] or [NotIncluded]
+	Outcome InclusionOutcome
+}
+
+func (e *CommitmentNotHonoredError) Error() string {
+	return fmt.Sprintf("%v: tx %v of request %v not included by slot %d (%v)", ErrCommitmentNotHonored, e.TxHash, e.RequestId, e.Slot, e.Outcome)
+}
+
+func (e *CommitmentNotHonoredError) Is(target error) bool {
+	return target == ErrCommitmentNotHonored
+}
+
+// InclusionConfig configures waiting for inclusion of preconfed tx. Receipt
+// polling interval and confirmation depth are taken from txmgr config
+// ReceiptQueryInterval and NumConfirmations.
+type InclusionConfig struct {
+	// Timeout bounds the wait for receipt and its confirmations, which
+	// starts once target slot passed. Waiting for the target slot is bounded
+	// only by ctx, so reservations of distant slots don't time out. Zero
+	// means no bound besides ctx.
+	Timeout time.Duration
+	// Grace is how long receipt is polled after target slot passed, before
+	// commitment is considered not honored.
+	Grace time.Duration
+}
+
+// DefaultInclusionConfig returns inclusion config used, unless
+// [WithInclusionConfig] is passed.
+func DefaultInclusionConfig() InclusionConfig {
+	return InclusionConfig{
+		Timeout: DefaultInclusionTimeout,
+		Grace:   DefaultInclusionGrace,
+	}
+}
+
+func (m *PreconfTxMgr) receiptInterval() time.Duration {
+	if m.cfg.ReceiptQueryInterval == 0 {
+		return time.Second
+	}
+	return m.cfg.ReceiptQueryInterval
+}
+
+// waitForInclusion waits until target slot passes and returns receipt of tx
+// once it has enough confirmations. Outcome is reported to the inclusion
+// reporter. Tx is considered included, even if it landed in another slot,
+// but it's an error, when it wasn't included at all.
+func (m *PreconfTxMgr) waitForInclusion(ctx context.Context, reservation *client.Reservation, commitment *luban.Commitment, tx *types.Transaction) (*types.Receipt, InclusionOutcome, error) {
+	slot := reservation.TargetSlot()
+	l := m.l.New("id", reservation.Id, "slot", slot, "tx", tx.Hash())
+
+	l.Debug("Waiting for preconf")
+	if _, err := beacon.WaitForHead(ctx, m.beacon, slot+1, beacon.DefaultPollInterval); err != nil {
+		return nil, 0, fmt.Errorf("Failed getting head, while waiting for preconf to fire: %w", err)
+	}
+
+	if m.inclusion.Timeout != 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, m.inclusion.Timeout)
+		defer cancel()
+	}
+
+	block, err := m.targetBlock(ctx, slot)
+	if err != nil {
+		return nil, 0, fmt.Errorf("Failed getting block of target slot: %w", err)
+	}
+
+	report := InclusionReport{
+		Reservation: reservation,
+		Commitment:  commitment,
+		Tx:          tx,
+		Block:       block,
+	}
+	deadline := time.Now().Add(m.inclusion.Grace)
+	for {
+		receipt, err := m.backend.TransactionReceipt(ctx, tx.Hash())
+		if receipt != nil && err == nil {
+			if m.confirmed(ctx, receipt) {
+				report.Receipt = receipt
+				switch {
+				case block == nil:
+					report.Outcome = SlotMissed
+				case receipt.BlockHash == block.BlockHash:
+					report.Outcome = IncludedOnTime
+				default:
+					report.Outcome = IncludedLate
+				}
+				m.reportInclusion(ctx, l, report)
+				return receipt, report.Outcome, nil
+			}
+			l.Debug("Waiting for confirmations", "block", receipt.BlockNumber)
+		} else if err == nil || errors.Is(err, ethereum.NotFound) {
+			if time.Now().After(deadline) {
+				report.Outcome = NotIncluded
+				if block == nil {
+					report.Outcome = SlotMissed
+				}
+				m.reportInclusion(ctx, l, report)
+				return nil, report.Outcome, &CommitmentNotHonoredError{
+					Slot:      slot,
+					RequestId: reservation.Id,
+					TxHash:    tx.Hash(),
+					Outcome:   report.Outcome,
+				}
+			}
+		} else {
+			l.Warn("Failed to query receipt", "err", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, 0, fmt.Errorf("Waiting for preconfed tx inclusion failed: %w", ctx.Err())
+		case <-time.After(m.receiptInterval()):
+		}
+	}
+}
+
+// targetBlock returns beacon block of the slot, or nil if slot was missed.
+// Other errors are retried until ctx is done.
+func (m *PreconfTxMgr) targetBlock(ctx context.Context, slot uint64) (*beacon.Block, error) {
+	for {
+		cCtx, cancel := context.WithTimeout(ctx, m.cfg.NetworkTimeout)
+		block, err := m.beacon.BlockBySlot(cCtx, slot)
+		cancel()
+		if errors.Is(err, beacon.ErrBlockNotFound) {
+			return nil, nil
+		} else if err == nil {
+			return block, nil
+		}
+		m.l.Warn("Failed to get beacon block", "slot", slot, "err", err)
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(m.receiptInterval()):
+		}
+	}
+}
+
+func (m *PreconfTxMgr) reportInclusion(ctx context.Context, l log.Logger, report InclusionReport) {
+	if report.Outcome == IncludedOnTime {
+		l.Debug("Preconf honored", "outcome", report.Outcome)
+	} else {
+		l.Warn("Preconf not honored", "outcome", report.Outcome)
+		m.collectInclusionEvidence(ctx, report)
+	}
+	if m.reporter != nil {
+		m.reporter(report)
+	}
+}
+
+// confirmed reports whether receipt is at least NumConfirmations deep.
+func (m *PreconfTxMgr) confirmed(ctx context.Context, receipt *types.Receipt) bool {
+	if m.cfg.NumConfirmations <= 1 {
+		return true
+	}
+	tip, err := m.backend.BlockNumber(ctx)
+	if err != nil {
+		m.l.Warn("Failed to get tip height", "err", err)
+		return false
+	}
+	return receipt.BlockNumber.Uint64()+m.cfg.NumConfirmations <= tip+1
+}
@@ -0,0 +1,114 @@
+package txmgr
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/ethereum/go-ethereum/params"
+
+	"github.com/risechain/luban-api/beacon"
+	"github.com/risechain/luban-api/slashing"
+)
+
+// Option configures [PreconfTxMgr].
+type Option func(*PreconfTxMgr)
+
+// WithSlotClock makes tx manager compute current slot locally, instead of
+// asking beacon node for head slot on every slot selection. It is also used
+// to expire fee quotes at slot start.
+func WithSlotClock(clock *beacon.SlotClock) Option {
+	return func(m *PreconfTxMgr) {
+		m.slots = clock
+	}
+}
+
+// WithInclusionConfig replaces [DefaultInclusionConfig].
+func WithInclusionConfig(cfg InclusionConfig) Option {
+	return func(m *PreconfTxMgr) {
+		m.inclusion = cfg
+	}
+}
+
+// WithInclusionReporter sets function called with outcome of every
+// preconfed tx, once it's known.
+func WithInclusionReporter(fn func(InclusionReport)) Option {
+	return func(m *PreconfTxMgr) {
+		m.reporter = fn
+	}
+}
+
+// WithSlashing makes tx manager collect evidence of every broken commitment.
+func WithSlashing(collector *slashing.Collector) Option {
+	return func(m *PreconfTxMgr) {
+		m.slashing = collector
+	}
+}
+
+// WithFallback makes tx manager broadcast tx to the public mempool, when
+// preconfirmation fails according to the policy.
+func WithFallback(policy FallbackPolicy) Option {
+	return func(m *PreconfTxMgr) {
+		m.fallback = policy
+	}
+}
+
+// WithChainConfig sets config of L1 chain, which decides fork rules of base
+// fee and blob fee. By default it's looked up by ChainID of txmgr config
+// among known networks.
+func WithChainConfig(config *params.ChainConfig) Option {
+	return func(m *PreconfTxMgr) {
+		m.chain = config
+	}
+}
+
+// WithBlobSchedule replaces [DefaultBlobSchedule], e.g. for devnets with
+// other blob targets. It panics, if schedule is invalid.
+func WithBlobSchedule(schedule BlobSchedule) Option {
+	if err := schedule.Validate(); err != nil {
+		panic(err)
+	}
+	return func(m *PreconfTxMgr) {
+		m.blobs = schedule
+	}
+}
+
+// WithFeeSafetyMultiplier replaces [DefaultFeeSafetyMultiplier], which is
+// applied to the worst-case base fee and blob fee projected to the target
+// slot. It panics, unless multiplier is finite and at least 1, since lower
+// one would undercut the projection.
+func WithFeeSafetyMultiplier(multiplier float64) Option {
+	if math.IsNaN(multiplier) || math.IsInf(multiplier, 0) || multiplier < 1 {
+		panic(fmt.Sprintf("invalid fee safety multiplier %v", multiplier))
+	}
+	return func(m *PreconfTxMgr) {
+		m.feeMultiplier = multiplier
+	}
+}
+
+// WithFeePolicy sets how tip and fee caps of crafted tx are chosen.
+func WithFeePolicy(policy FeePolicy) Option {
+	return func(m *PreconfTxMgr) {
+		m.feePolicy = policy
+	}
+}
+
+// WithPricingStrategy replaces [SpecPricing] of blockspace reservations.
+func WithPricingStrategy(strategy PricingStrategy) Option {
+	return func(m *PreconfTxMgr) {
+		m.pricing = strategy
+	}
+}
+
+// WithSlotSelector replaces [EarliestSlot] choice of target slot.
+func WithSlotSelector(selector SlotSelector) Option {
+	return func(m *PreconfTxMgr) {
+		m.selector = selector
+	}
+}
+
+// WithRetryPolicy replaces [DefaultRetryPolicy] of failed preconf attempts.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(m *PreconfTxMgr) {
+		m.retry = policy
+	}
+}
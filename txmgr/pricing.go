@@ -0,0 +1,127 @@
+package txmgr
+
+import (
+	"errors"
+	"fmt"
+
+	u256 "github.com/holiman/uint256"
+
+	"github.com/ethereum/go-ethereum/core/types"
+
+	luban "github.com/risechain/luban-api/types"
+)
+
+var (
+	// ErrOverBudget is returned, when deposit and tip of a reservation
+	// exceed budget of [BudgetPricing].
+	ErrOverBudget = errors.New("reservation price over budget")
+	// ErrNoBudget is returned by [BudgetPricing] without budget.
+	ErrNoBudget = errors.New("pricing budget not set")
+)
+
+// Quote is a preconf fee quoted by the gateway for a slot.
+type Quote struct {
+	Slot       uint64
+	GasFee     uint64
+	BlobGasFee uint64
+}
+
+// PricingStrategy decides deposit and tip of blockspace reservation for tx.
+type PricingStrategy interface {
+	// Price returns deposit and tip for reservation of tx at quoted slot.
+	// Attempt is the number of failed attempts to preconfirm tx so far.
+	Price(tx *types.Transaction, quote Quote, attempt int) (deposit, tip *u256.Int, err error)
+}
+
+var (
+	_ PricingStrategy = SpecPricing{}
+	_ PricingStrategy = TipMultiplierPricing{}
+	_ PricingStrategy = EscalatingTipPricing{}
+	_ PricingStrategy = BudgetPricing{}
+)
+
+// SpecPricing prices reservation with [luban.ComputeDeposit] and
+// [luban.ComputeTip]. It's used, unless [WithPricingStrategy] is passed.
+type SpecPricing struct{}
+
+func (SpecPricing) Price(tx *types.Transaction, quote Quote, attempt int) (*u256.Int, *u256.Int, error) {
+	gasFee, blobGasFee := u256.NewInt(quote.GasFee), u256.NewInt(quote.BlobGasFee)
+	nBlobs := uint32(len(tx.BlobHashes()))
+	deposit, err := luban.ComputeDeposit(tx.Gas(), nBlobs, gasFee, blobGasFee)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to compute deposit: %w", err)
+	}
+	tip, err := luban.ComputeTip(tx.Gas(), nBlobs, gasFee, blobGasFee)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to compute tip: %w", err)
+	}
+	return deposit, tip, nil
+}
+
+// mulPercent returns x * percent / 100.
+func mulPercent(x *u256.Int, percent uint64) (*u256.Int, error) {
+	res, overflow := new(u256.Int).MulOverflow(x, u256.NewInt(percent))
+	if overflow {
+		return nil, luban.ErrPriceOverflow
+	}
+	return res.Div(res, u256.NewInt(100)), nil
+}
+
+// TipMultiplierPricing pays tip of Percent of the spec tip, e.g. 150 pays
+// 1.5x the quote. Zero Percent pays the spec tip. Deposit follows the spec.
+type TipMultiplierPricing struct {
+	Percent uint64
+}
+
+func (p TipMultiplierPricing) Price(tx *types.Transaction, quote Quote, attempt int) (*u256.Int, *u256.Int, error) {
+	deposit, tip, err := SpecPricing{}.Price(tx, quote, attempt)
+	if err != nil {
+		return nil, nil, err
+	}
+	percent := p.Percent
+	if percent == 0 {
+		percent = 100
+	}
+	if tip, err = mulPercent(tip, percent); err != nil {
+		return nil, nil, fmt.Errorf("failed to multiply tip: %w", err)
+	}
+	return deposit, tip, nil
+}
+
+// EscalatingTipPricing raises the spec tip by StepPercent on every failed
+// attempt, so retries outbid competing reservations. Deposit follows the
+// spec.
+type EscalatingTipPricing struct {
+	StepPercent uint64
+}
+
+func (p EscalatingTipPricing) Price(tx *types.Transaction, quote Quote, attempt int) (*u256.Int, *u256.Int, error) {
+	return TipMultiplierPricing{Percent: 100 + p.StepPercent*uint64(attempt)}.Price(tx, quote, attempt)
+}
+
+// BudgetPricing caps deposit plus tip of the wrapped strategy by Budget.
+// Reservations over the budget fail with [ErrOverBudget], and without budget
+// with [ErrNoBudget]. Nil Strategy is [SpecPricing].
+type BudgetPricing struct {
+	Strategy PricingStrategy
+	Budget   *u256.Int
+}
+
+func (p BudgetPricing) Price(tx *types.Transaction, quote Quote, attempt int) (*u256.Int, *u256.Int, error) {
+	if p.Budget == nil {
+		return nil, nil, ErrNoBudget
+	}
+	strategy := p.Strategy
+	if strategy == nil {
+		strategy = SpecPricing{}
+	}
+	deposit, tip, err := strategy.Price(tx, quote, attempt)
+	if err != nil {
+		return nil, nil, err
+	}
+	total, overflow := new(u256.Int).AddOverflow(deposit, tip)
+	if overflow || total.Cmp(p.Budget) > 0 {
+		return nil, nil, fmt.Errorf("%w: deposit %v and tip %v exceed %v", ErrOverBudget, deposit, tip, p.Budget)
+	}
+	return deposit, tip, nil
+}
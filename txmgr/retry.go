@@ -0,0 +1,166 @@
+package txmgr
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrRetriesExhausted is returned, when preconfirmation attempts exceed
+// the retry policy. Errors matching it are [*RetriesExhaustedError].
+var ErrRetriesExhausted = errors.New("preconf retries exhausted")
+
+// RetryPolicy bounds retries of failed slot selection, pricing, reservation
+// and submission.
+type RetryPolicy struct {
+	// MaxAttempts bounds the number of attempts. Zero means no bound.
+	MaxAttempts int
+	// MinBackoff is the wait after the first failure, which doubles after
+	// every next one up to MaxBackoff. Zero means retrying immediately.
+	// Zero MaxBackoff means no cap. Wait never outlasts Deadline.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// Deadline bounds all attempts since the start of sending. Zero means
+	// no bound besides ctx.
+	Deadline time.Duration
+	// ExcludeFailedSlots makes slots, which failed once, not selected again
+	// for the same tx.
+	ExcludeFailedSlots bool
+}
+
+// DefaultRetryPolicy returns retry policy used, unless [WithRetryPolicy] is
+// passed.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:        10,
+		MinBackoff:         100 * time.Millisecond,
+		MaxBackoff:         5 * time.Second,
+		ExcludeFailedSlots: true,
+	}
+}
+
+// bounded reports whether the policy eventually stops retrying.
+func (p *RetryPolicy) bounded() bool {
+	return p.MaxAttempts != 0 || p.Deadline != 0
+}
+
+// AttemptStage is the step, at which attempt to preconfirm tx failed.
+type AttemptStage string
+
+const (
+	StageSelectSlot AttemptStage = "select slot"
+	StagePrice      AttemptStage = "price"
+	StageReserve    AttemptStage = "reserve"
+	StageSubmit     AttemptStage = "submit"
+)
+
+// Attempt is a failed attempt to preconfirm tx.
+type Attempt struct {
+	Stage AttemptStage
+	// Slot is zero, if no slot was selected
+	Slot uint64
+	// RequestId is zero, if no blockspace was reserved
+	RequestId uuid.UUID
+	Err       error
+}
+
+func (a Attempt) String() string {
+	var b strings.Builder
+	b.WriteString(string(a.Stage))
+	if a.Slot != 0 {
+		fmt.Fprintf(&b, " slot %d", a.Slot)
+	}
+	if a.RequestId != uuid.Nil {
+		fmt.Fprintf(&b, " request %v", a.RequestId)
+	}
+	fmt.Fprintf(&b, ": %v", a.Err)
+	return b.String()
+}
+
+// RetriesExhaustedError lists every failed attempt to preconfirm tx.
+type RetriesExhaustedError struct {
+	// Reason tells which bound of the policy was hit
+	Reason   string
+	Attempts []Attempt
+}
+
+func (e *RetriesExhaustedError) Error() string {
+	attempts := make([]string, len(e.Attempts))
+	for i, a := range e.Attempts {
+		attempts[i] = fmt.Sprintf("#%d %v", i+1, a)
+	}
+	return fmt.Sprintf("%v: %s after %d attempts: %s", ErrRetriesExhausted, e.Reason, len(e.Attempts), strings.Join(attempts, "; "))
+}
+
+func (e *RetriesExhaustedError) Is(target error) bool {
+	return target == ErrRetriesExhausted
+}
+
+// Unwrap returns errors of all attempts.
+func (e *RetriesExhaustedError) Unwrap() []error {
+	errs := make([]error, len(e.Attempts))
+	for i, a := range e.Attempts {
+		errs[i] = a.Err
+	}
+	return errs
+}
+
+// retryState tracks failed attempts of a single tx.
+type retryState struct {
+	policy   RetryPolicy
+	start    time.Time
+	backoff  time.Duration
+	attempts []Attempt
+	excluded map[uint64]bool
+}
+
+func newRetryState(policy RetryPolicy) *retryState {
+	return &retryState{
+		policy:   policy,
+		start:    time.Now(),
+		backoff:  policy.MinBackoff,
+		excluded: make(map[uint64]bool),
+	}
+}
+
+// fail records failed attempt and excludes its slot, if policy says so.
+func (s *retryState) fail(a Attempt) {
+	s.attempts = append(s.attempts, a)
+	if s.policy.ExcludeFailedSlots && a.Slot != 0 {
+		s.excluded[a.Slot] = true
+	}
+}
+
+// exhausted returns error, if policy doesn't allow another attempt.
+func (s *retryState) exhausted() *RetriesExhaustedError {
+	reason := ""
+	switch {
+	case s.policy.MaxAttempts != 0 && len(s.attempts) >= s.policy.MaxAttempts:
+		reason = fmt.Sprintf("max %d attempts reached", s.policy.MaxAttempts)
+	case s.policy.Deadline != 0 && time.Since(s.start) >= s.policy.Deadline:
+		reason = fmt.Sprintf("deadline of %v passed", s.policy.Deadline)
+	default:
+		return nil
+	}
+	return &RetriesExhaustedError{Reason: reason, Attempts: s.attempts}
+}
+
+// wait returns the backoff before the next attempt, bounded by time left
+// till the deadline, and doubles it.
+func (s *retryState) wait() time.Duration {
+	backoff := s.backoff
+	if s.backoff <= math.MaxInt64/2 {
+		s.backoff *= 2
+	}
+	if s.policy.MaxBackoff != 0 {
+		s.backoff = min(s.backoff, s.policy.MaxBackoff)
+	}
+	if s.policy.Deadline != 0 {
+		backoff = max(min(backoff, s.policy.Deadline-time.Since(s.start)), 0)
+	}
+	return backoff
+}
@@ -0,0 +1,52 @@
+package txmgr
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// PreconfTxmgrAPI exposes fee minimums and fee limit threshold of
+// PreconfTxMgr over RPC in the same "txmgr" namespace as op-service
+// SimpleTxManager does.
+type PreconfTxmgrAPI struct {
+	mgr *PreconfTxMgr
+	l   log.Logger
+}
+
+func (a *PreconfTxmgrAPI) GetMinBaseFee(_ context.Context) *big.Int {
+	return a.mgr.cfg.MinBaseFee.Load()
+}
+
+func (a *PreconfTxmgrAPI) SetMinBaseFee(_ context.Context, val *big.Int) {
+	a.mgr.cfg.MinBaseFee.Store(val)
+	a.l.Info("txmgr config val changed: SetMinBaseFee", "new_val", val)
+}
+
+func (a *PreconfTxmgrAPI) GetMinPriorityFee(_ context.Context) *big.Int {
+	return a.mgr.cfg.MinTipCap.Load()
+}
+
+func (a *PreconfTxmgrAPI) SetMinPriorityFee(_ context.Context, val *big.Int) {
+	a.mgr.cfg.MinTipCap.Store(val)
+	a.l.Info("txmgr config val changed: SetMinPriorityFee", "new_val", val)
+}
+
+func (a *PreconfTxmgrAPI) GetMinBlobFee(_ context.Context) *big.Int {
+	return a.mgr.cfg.MinBlobTxFee.Load()
+}
+
+func (a *PreconfTxmgrAPI) SetMinBlobFee(_ context.Context, val *big.Int) {
+	a.mgr.cfg.MinBlobTxFee.Store(val)
+	a.l.Info("txmgr config val changed: SetMinBlobFee", "new_val", val)
+}
+
+func (a *PreconfTxmgrAPI) GetFeeThreshold(_ context.Context) *big.Int {
+	return a.mgr.cfg.FeeLimitThreshold.Load()
+}
+
+func (a *PreconfTxmgrAPI) SetFeeThreshold(_ context.Context, val *big.Int) {
+	a.mgr.cfg.FeeLimitThreshold.Store(val)
+	a.l.Info("txmgr config val changed: SetFeeThreshold", "new_val", val)
+}
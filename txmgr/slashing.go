@@ -0,0 +1,89 @@
+package txmgr
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/risechain/luban-api/client"
+	"github.com/risechain/luban-api/slashing"
+)
+
+// evidenceSubmitTimeout bounds submission of evidence in the background
+const evidenceSubmitTimeout = time.Minute
+
+var outcomeKinds = map[InclusionOutcome]slashing.Kind{
+	IncludedLate: slashing.IncludedLate,
+	SlotMissed:   slashing.SlotMissed,
+	NotIncluded:  slashing.NotIncluded,
+}
+
+func newEvidence(kind slashing.Kind, reservation *client.Reservation, tx *types.Transaction) *slashing.Evidence {
+	return &slashing.Evidence{
+		Kind:               kind,
+		RequestId:          reservation.Id,
+		TargetSlot:         reservation.TargetSlot(),
+		Request:            reservation.Request,
+		RequestSignature:   reservation.Signature.String(),
+		ReservationReceipt: reservation.Receipt,
+		Tx:                 tx,
+	}
+}
+
+// collectSubmissionEvidence collects evidence of gateway failing to accept
+// tx for the reservation. Only errors returned by the gateway are evidence,
+// transport failures and cancellation on our side aren't.
+func (m *PreconfTxMgr) collectSubmissionEvidence(ctx context.Context, reservation *client.Reservation, tx *types.Transaction, err error) {
+	var gerr *client.GatewayError
+	if !errors.As(err, &gerr) {
+		return
+	}
+	ev := newEvidence(slashing.SubmissionFailed, reservation, tx)
+	ev.Reason = err.Error()
+	m.collectEvidence(ctx, ev)
+}
+
+// collectInclusionEvidence collects evidence of commitment, which wasn't
+// honored on time.
+func (m *PreconfTxMgr) collectInclusionEvidence(ctx context.Context, report InclusionReport) {
+	kind, ok := outcomeKinds[report.Outcome]
+	if !ok {
+		return
+	}
+	ev := newEvidence(kind, report.Reservation, report.Tx)
+	ev.Commitment = report.Commitment
+	ev.BeaconBlock = report.Block
+	ev.TxReceipt = report.Receipt
+	if report.Block != nil {
+		header, err := m.backend.HeaderByNumber(ctx, new(big.Int).SetUint64(report.Block.BlockNumber))
+		if err != nil {
+			m.l.Warn("Failed to get execution block for evidence", "id", ev.RequestId, "block", report.Block.BlockNumber, "err", err)
+		}
+		ev.ExecutionBlock = header
+	}
+	m.collectEvidence(ctx, ev)
+}
+
+// collectEvidence persists evidence and submits it in the background, so
+// slow submitter doesn't hold sending. Failed submissions are left to
+// [slashing.Collector.RetryPending].
+func (m *PreconfTxMgr) collectEvidence(ctx context.Context, ev *slashing.Evidence) {
+	if m.slashing == nil {
+		return
+	}
+	if err := m.slashing.Save(ev); err != nil {
+		m.l.Error("Failed to collect slashing evidence", "id", ev.RequestId, "kind", ev.Kind, "err", err)
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), evidenceSubmitTimeout)
+		defer cancel()
+		if err := m.slashing.Submit(ctx, ev); err != nil {
+			m.l.Warn("Failed to submit slashing evidence", "id", ev.RequestId, "kind", ev.Kind, "err", err)
+		}
+	}()
+}
@@ -0,0 +1,155 @@
+package txmgr
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	u256 "github.com/holiman/uint256"
+
+	"github.com/risechain/luban-api/beacon"
+	"github.com/risechain/luban-api/client"
+	luban "github.com/risechain/luban-api/types"
+)
+
+// SlotRequest is passed to [SlotSelector] to choose target slot of tx.
+type SlotRequest struct {
+	// Slots offered by the gateway, which have enough gas, blobs and
+	// constraints for tx and start late enough to be reserved, in
+	// increasing order. It is never empty.
+	Slots     []luban.SlotInfo
+	GasLimit  uint64
+	BlobCount uint32
+	// Fees quotes slots. Quotes are cached, so reservation doesn't fetch
+	// them again.
+	Fees  *client.FeeCache
+	Clock *beacon.SlotClock
+}
+
+// SlotSelector chooses target slot of tx. It returns [ErrNoSlotsAvailable],
+// if none of the slots is acceptable.
+type SlotSelector interface {
+	SelectSlot(ctx context.Context, req SlotRequest) (uint64, error)
+}
+
+var (
+	_ SlotSelector = EarliestSlot{}
+	_ SlotSelector = CheapestSlot{}
+	_ SlotSelector = HeadroomSlot{}
+	_ SlotSelector = DeadlineSlot{}
+)
+
+// fitsSlot reports whether tx fits remaining capacity of the slot. Every
+// reservation takes one constraint, if gateway limits them.
+func fitsSlot(s luban.SlotInfo, gasLimit uint64, blobCount uint32) bool {
+	if s.ConstraintsAvailable != nil && *s.ConstraintsAvailable == 0 {
+		return false
+	}
+	return s.GasAvailable >= gasLimit && s.BlobsAvailable >= blobCount
+}
+
+// EarliestSlot chooses the first slot. It's used, unless [WithSlotSelector]
+// is passed.
+type EarliestSlot struct{}
+
+func (EarliestSlot) SelectSlot(ctx context.Context, req SlotRequest) (uint64, error) {
+	return req.Slots[0].Slot, nil
+}
+
+// CheapestSlot chooses slot with the lowest quoted cost of tx among the
+// first Window slots. Zero Window means all slots. Ties go to the earlier
+// slot.
+type CheapestSlot struct {
+	Window int
+}
+
+func (s CheapestSlot) SelectSlot(ctx context.Context, req SlotRequest) (uint64, error) {
+	slots := req.Slots
+	if s.Window > 0 && len(slots) > s.Window {
+		slots = slots[:s.Window]
+	}
+	nums := make([]uint64, len(slots))
+	for i, slot := range slots {
+		nums[i] = slot.Slot
+	}
+	// Failed quotes are tolerated, as long as some slot is quoted
+	quotes, err := req.Fees.GetPreconfFees(ctx, nums)
+	if len(quotes) == 0 {
+		return 0, fmt.Errorf("failed to quote slots: %w", err)
+	}
+
+	var (
+		best     uint64
+		bestCost *u256.Int
+	)
+	for _, slot := range nums {
+		quote, ok := quotes[slot]
+		if !ok {
+			continue
+		}
+		cost, err := luban.ComputeDeposit(req.GasLimit, req.BlobCount, u256.NewInt(quote.GasFee), u256.NewInt(quote.BlobGasFee))
+		if err != nil {
+			continue
+		}
+		if bestCost == nil || cost.Lt(bestCost) {
+			best, bestCost = slot, cost
+		}
+	}
+	if bestCost == nil {
+		return 0, ErrNoSlotsAvailable
+	}
+	return best, nil
+}
+
+// HeadroomSlot chooses slot with the most gas left after tx, which is the
+// least likely to be raced. Ties go to more blobs left, then to the earlier
+// slot.
+type HeadroomSlot struct{}
+
+func (HeadroomSlot) SelectSlot(ctx context.Context, req SlotRequest) (uint64, error) {
+	best := req.Slots[0]
+	for _, s := range req.Slots[1:] {
+		if s.GasAvailable > best.GasAvailable || s.GasAvailable == best.GasAvailable && s.BlobsAvailable > best.BlobsAvailable {
+			best = s
+		}
+	}
+	return best.Slot, nil
+}
+
+// DeadlineSlot limits slots to the ones, which end before the deadline of
+// ctx or MaxDelay from now, whichever is earlier, and lets Then choose among
+// them. Nil Then means [EarliestSlot], zero MaxDelay means only ctx
+// deadline applies.
+type DeadlineSlot struct {
+	MaxDelay time.Duration
+	Then     SlotSelector
+}
+
+func (s DeadlineSlot) SelectSlot(ctx context.Context, req SlotRequest) (uint64, error) {
+	deadline, ok := ctx.Deadline()
+	if s.MaxDelay != 0 {
+		if maxDeadline := req.Clock.Now().Add(s.MaxDelay); !ok || maxDeadline.Before(deadline) {
+			deadline, ok = maxDeadline, true
+		}
+	}
+
+	slots := req.Slots
+	if ok {
+		slots = nil
+		for _, slot := range req.Slots {
+			if !req.Clock.SlotStart(slot.Slot + 1).After(deadline) {
+				slots = append(slots, slot)
+			}
+		}
+	}
+	if len(slots) == 0 {
+		return 0, fmt.Errorf("%w: no slot ends before %v", ErrNoSlotsAvailable, deadline)
+	}
+
+	then := s.Then
+	if then == nil {
+		then = EarliestSlot{}
+	}
+	req.Slots = slots
+	return then.SelectSlot(ctx, req)
+}
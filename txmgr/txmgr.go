@@ -2,13 +2,12 @@ package txmgr
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"math/big"
-	"net/http"
-	"strconv"
+	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
@@ -17,16 +16,18 @@ import (
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
-	"github.com/ethereum/go-ethereum/consensus/misc/eip1559"
-	"github.com/ethereum/go-ethereum/consensus/misc/eip4844"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
 
 	"github.com/ethereum-optimism/optimism/op-service/errutil"
 	"github.com/ethereum-optimism/optimism/op-service/retry"
 	"github.com/ethereum-optimism/optimism/op-service/txmgr"
 
+	"github.com/risechain/luban-api/beacon"
+	"github.com/risechain/luban-api/client"
+	"github.com/risechain/luban-api/slashing"
 	luban "github.com/risechain/luban-api/types"
 )
 
@@ -34,11 +35,20 @@ type PreconfClient interface {
 	GetSlots(ctx context.Context) ([]luban.SlotInfo, error)
 	GetPreconfFee(ctx context.Context, slot uint64) (uint64, uint64, error)
 
-	ReserveBlockspace(ctx context.Context, req luban.ReserveBlockSpaceRequest) (uuid.UUID, error)
+	// ReserveBlockspace may return reservation built by other client than
+	// [client.Client], since only its data is used.
+	ReserveBlockspace(ctx context.Context, req luban.ReserveBlockSpaceRequest) (*client.Reservation, error)
 
-	SubmitTransaction(ctx context.Context, reqId uuid.UUID, tx *types.Transaction) error
+	SubmitTransaction(ctx context.Context, reqId uuid.UUID, tx *types.Transaction) (luban.Commitment, error)
 }
 
+var (
+	_ PreconfClient = (*client.Client)(nil)
+	_ PreconfClient = (*client.MultiClient)(nil)
+)
+
+var _ txmgr.TxManager = (*PreconfTxMgr)(nil)
+
 type ETHBackend interface {
 	txmgr.ETHBackend
 
@@ -48,201 +58,479 @@ type ETHBackend interface {
 type PreconfTxMgr struct {
 	backend ETHBackend
 	client  PreconfClient
+	fees    *client.FeeCache
 
 	l   log.Logger
 	cfg *txmgr.Config
 
+	beacon beacon.BeaconClient
+	slots  *beacon.SlotClock
+	// beaconSlots is slot clock fetched from beacon, unless slots is set
+	beaconSlots     *beacon.SlotClock
+	beaconSlotsLock sync.Mutex
+
+	inclusion InclusionConfig
+	reporter  func(InclusionReport)
+	slashing  *slashing.Collector
+	fallback  FallbackPolicy
+
+	chain         *params.ChainConfig
+	blobs         BlobSchedule
+	feeMultiplier float64
+	feePolicy     FeePolicy
+	pricing       PricingStrategy
+	selector      SlotSelector
+	retry         RetryPolicy
+
 	nonce     *uint64
-	beaconUrl string
 	nonceLock sync.RWMutex
-}
 
-func NewPreconfTxMgr(l log.Logger, backend ETHBackend, cfg *txmgr.Config, client PreconfClient, beaconUrl string) *PreconfTxMgr {
-	return &PreconfTxMgr{
-		backend:   backend,
-		client:    client,
-		l:         l,
-		cfg:       cfg,
-		beaconUrl: beaconUrl,
-	}
+	closed atomic.Bool
 }
 
-func (m *PreconfTxMgr) getHeadSlot() (uint64, error) {
-	url := fmt.Sprintf("%s/eth/v1/node/syncing", m.beaconUrl)
-
-	// Make the HTTP GET request
-	resp, err := http.Get(url)
-	if err != nil {
-		return 0, fmt.Errorf("failed to make GET request: %w", err)
+func NewPreconfTxMgr(l log.Logger, backend ETHBackend, cfg *txmgr.Config, preconfer PreconfClient, beacon beacon.BeaconClient, opts ...Option) *PreconfTxMgr {
+	m := &PreconfTxMgr{
+		backend: backend,
+		client:  preconfer,
+		l:       l,
+		cfg:     cfg,
+		beacon:  beacon,
+
+		inclusion:     DefaultInclusionConfig(),
+		feeMultiplier: DefaultFeeSafetyMultiplier,
+		pricing:       SpecPricing{},
+		selector:      EarliestSlot{},
+		retry:         DefaultRetryPolicy(),
+		blobs:         DefaultBlobSchedule(),
 	}
-	defer resp.Body.Close()
-
-	// Check for non-200 status codes
-	if resp.StatusCode != http.StatusOK {
-		return 0, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	for _, opt := range opts {
+		opt(m)
 	}
 
-	// Define a struct to match the JSON structure
-	var syncingResponse struct {
-		Data struct {
-			HeadSlot     string `json:"head_slot"`
-			SyncDistance string `json:"sync_distance"`
-			IsSyncing    bool   `json:"is_syncing"`
-			IsOptimistic bool   `json:"is_optimistic"`
-			ELOffline    bool   `json:"el_offline"`
-		} `json:"data"`
+	var timer client.SlotTimer
+	if m.slots != nil {
+		timer = m.slots
 	}
+	m.fees = client.NewFeeCache(preconfer, client.DefaultFeeTTL, timer)
+	return m
+}
 
-	// Decode the JSON response directly into the struct
-	if err := json.NewDecoder(resp.Body).Decode(&syncingResponse); err != nil {
-		return 0, fmt.Errorf("failed to decode JSON: %w", err)
+// headSlot returns current slot from slot clock, if it's set, or head slot
+// of beacon node otherwise.
+func (m *PreconfTxMgr) headSlot(ctx context.Context) (uint64, error) {
+	if m.slots != nil {
+		return m.slots.CurrentSlot(), nil
 	}
-
-	// Convert the head_slot to uint64
-	headSlot, err := strconv.ParseUint(syncingResponse.Data.HeadSlot, 10, 64)
-	if err != nil {
-		return 0, fmt.Errorf("invalid head_slot value: %w", err)
-	}
-
-	return headSlot, nil
+	cCtx, cancel := context.WithTimeout(ctx, m.cfg.NetworkTimeout)
+	defer cancel()
+	return m.beacon.HeadSlot(cCtx)
 }
 
-func (m *PreconfTxMgr) getSlotForCandidate(ctx context.Context, candidate *txmgr.TxCandidate) (uint64, error) {
+// getSlotForCandidate offers slots, which fit the candidate and aren't
+// excluded, to the slot selector.
+func (m *PreconfTxMgr) getSlotForCandidate(ctx context.Context, candidate *txmgr.TxCandidate, excluded map[uint64]bool) (uint64, error) {
 	slots, err := m.client.GetSlots(ctx)
 	// TODO: retry here or on a preconf client side?
 	if err != nil {
 		return 0, fmt.Errorf("geting slots for preconf failed: %w", err)
 	}
 
-	head, err := m.getHeadSlot()
+	head, err := m.headSlot(ctx)
 	if err != nil {
 		return 0, fmt.Errorf("geting head slot for preconf failed: %w", err)
 	}
+	m.fees.Prune(head)
 
-	nBlobs := uint32(len(candidate.Blobs))
-	slot := uint64(0)
+	req := SlotRequest{
+		GasLimit:  candidate.GasLimit,
+		BlobCount: uint32(len(candidate.Blobs)),
+		Fees:      m.fees,
+	}
 	for _, s := range slots {
 		// TODO: once luban fixes sending old slots remove it or
 		// filter only the first slot
 		if s.Slot <= head+1 {
 			continue
 		}
-		if s.BlobsAvailable < nBlobs {
-			continue
-		}
-		if s.GasAvailable < candidate.GasLimit {
+		if !fitsSlot(s, req.GasLimit, req.BlobCount) || excluded[s.Slot] {
 			continue
 		}
-		slot = s.Slot
-		break
+		req.Slots = append(req.Slots, s)
+	}
+	if len(req.Slots) == 0 {
+		return 0, ErrNoSlotsAvailable
 	}
-	if slot == 0 {
-		return 0, errors.New("No slots available for transaction")
+	sort.Slice(req.Slots, func(i, j int) bool {
+		return req.Slots[i].Slot < req.Slots[j].Slot
+	})
+	if req.Clock, err = m.slotClock(ctx); err != nil {
+		return 0, fmt.Errorf("failed to get slot clock: %w", err)
 	}
 
-	return slot, nil
+	return m.selector.SelectSlot(ctx, req)
 }
 
-// TODO: wrap in timeout?
+// Send reserves blockspace for the candidate, submits it to the gateway and
+// waits for its receipt.
+//
+// NOTE: Send can be called concurrently, the nonce will be managed internally.
 func (m *PreconfTxMgr) Send(ctx context.Context, candidate txmgr.TxCandidate) (*types.Receipt, error) {
+	res, err := m.SendWithResult(ctx, candidate)
+	if err != nil {
+		return nil, err
+	}
+	return res.Receipt, nil
+}
+
+// SendWithResult is [PreconfTxMgr.Send], which also tells whether tx was
+// preconfirmed or fell back to the mempool.
+func (m *PreconfTxMgr) SendWithResult(ctx context.Context, candidate txmgr.TxCandidate) (*SendResult, error) {
+	// refuse new requests if the tx manager is closed
+	if m.closed.Load() {
+		return nil, txmgr.ErrClosed
+	}
+
+	ctx, cancel := m.sendContext(ctx)
+	defer cancel()
+
 	tx, err := m.prepare(ctx, candidate)
 	if err != nil {
+		m.resetNonce()
 		return nil, fmt.Errorf("preparing tx failed: %w", err)
 	}
+	res, err := m.sendTx(ctx, tx, candidate)
+	if err != nil {
+		m.resetNonce()
+		return nil, err
+	}
+	return res, nil
+}
+
+// SendAsync crafts the transaction synchronously, so nonces follow the order
+// of calls, and preconfirms it in the background. The result is delivered to
+// ch, which must be buffered.
+func (m *PreconfTxMgr) SendAsync(ctx context.Context, candidate txmgr.TxCandidate, ch chan txmgr.SendResponse) {
+	if cap(ch) == 0 {
+		panic("SendAsync: channel must be buffered")
+	}
+
+	// refuse new requests if the tx manager is closed
+	if m.closed.Load() {
+		ch <- txmgr.SendResponse{Err: txmgr.ErrClosed}
+		return
+	}
+
+	ctx, cancel := m.sendContext(ctx)
+
+	tx, err := m.prepare(ctx, candidate)
+	if err != nil {
+		m.resetNonce()
+		cancel()
+		ch <- txmgr.SendResponse{Err: fmt.Errorf("preparing tx failed: %w", err)}
+		return
+	}
+
+	go func() {
+		defer cancel()
+		var receipt *types.Receipt
+		res, err := m.sendTx(ctx, tx, candidate)
+		if err != nil {
+			m.resetNonce()
+		} else {
+			receipt = res.Receipt
+		}
+		ch <- txmgr.SendResponse{
+			Receipt: receipt,
+			Nonce:   tx.Nonce(),
+			Err:     err,
+		}
+	}()
+}
+
+func (m *PreconfTxMgr) sendContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if m.cfg.TxSendTimeout == 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, m.cfg.TxSendTimeout)
+}
 
-	var slot uint64
+// sendTx reserves blockspace for already crafted tx, submits it and waits
+// for the target slot to pass. It falls back to the mempool according to the
+// fallback policy.
+func (m *PreconfTxMgr) sendTx(ctx context.Context, tx *types.Transaction, candidate txmgr.TxCandidate) (*SendResult, error) {
+	var (
+		slot        uint64
+		reservation *client.Reservation
+		commitment  *luban.Commitment
+		err         error
+
+		res       = &SendResult{Path: PathPreconf}
+		start     = time.Now()
+		retry     = newRetryState(m.retry)
+		submitted bool
+	)
 
 	nBlobs := uint32(len(candidate.Blobs))
 
 	for {
-		slot, err = m.getSlotForCandidate(ctx, &candidate)
-		// XXX: Figure out if we should wait till next slot or it should be fatal
-		if err != nil {
+		failures := len(retry.attempts)
+		res.Reservation = reservation
+		if m.fallback.failuresExhausted(failures) {
+			return m.sendToMempool(ctx, tx, res, fmt.Sprintf("%d preconf attempts failed", failures))
+		}
+		if m.fallback.deadlinePassed(start) {
+			return m.sendToMempool(ctx, tx, res, "preconf deadline passed")
+		}
+		if failures > 0 {
+			if exhausted := retry.exhausted(); exhausted != nil && m.fallback.bounded() {
+				return m.sendToMempool(ctx, tx, res, fmt.Sprintf("%v: %s", ErrRetriesExhausted, exhausted.Reason))
+			} else if exhausted != nil {
+				return nil, exhausted
+			}
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(retry.wait()):
+			}
+		}
+
+		slot, err = m.getSlotForCandidate(ctx, &candidate, retry.excluded)
+		if errors.Is(err, ErrNoSlotsAvailable) && m.fallback.OnNoSlots {
+			return m.sendToMempool(ctx, tx, res, "no slots available")
+		} else if err != nil && ((m.retry.bounded() || m.fallback.bounded()) && !errors.Is(err, ErrNoSlotsAvailable) || len(retry.excluded) != 0) {
+			// Failures to get slots are retried, as long as retries are
+			// bounded. Excluded slots may leave none, till gateway offers
+			// new ones.
+			m.l.Warn("Getting slot for preconf failed. Retrying...", "err", err)
+			retry.fail(Attempt{Stage: StageSelectSlot, Err: err})
+			continue
+		} else if err != nil {
+			// XXX: Figure out if we should wait till next slot or it should be fatal
 			return nil, fmt.Errorf("Failed to get slot for preconf: %w", err)
 		}
 		m.l.Debug("Got slot for preconf", "slot", slot)
 
-		gasPrice, blobPrice, err := m.client.GetPreconfFee(ctx, slot)
+		// Once submitted, tx can't be replaced without risking the
+		// commitment
+		if !submitted {
+			priced, err := m.priceForSlot(ctx, tx, slot)
+			if errors.Is(err, ErrFeeLimit) || errors.Is(err, ErrBlobsBeforeCancun) {
+				return nil, fmt.Errorf("Failed to price tx for slot %d: %w", slot, err)
+			}
+			if err != nil {
+				m.l.Warn("Pricing tx for slot failed. Retrying...", "slot", slot, "err", err)
+				retry.fail(Attempt{Stage: StagePrice, Slot: slot, Err: fmt.Errorf("failed to price tx for slot: %w", err)})
+				continue
+			}
+			tx = priced
+		}
+
+		gasPrice, blobPrice, err := m.fees.GetPreconfFee(ctx, slot)
 		if err != nil {
-			return nil, fmt.Errorf("Failed to get preconf fee: %w", err)
+			m.l.Warn("Getting preconf fee failed. Retrying...", "slot", slot, "err", err)
+			retry.fail(Attempt{Stage: StagePrice, Slot: slot, Err: fmt.Errorf("failed to get preconf fee: %w", err)})
+			continue
 		}
 		m.l.Debug("Got preconf fee", "gasPrice", gasPrice, "blobPrice", blobPrice)
 
-		// { gas_limit * gas_fee + blob_count * blob_gas_fee } * 0.5
-		gas := u256.NewInt(tx.Gas())
-		gas = gas.Mul(gas, u256.NewInt(gasPrice))
-		blob := u256.NewInt(uint64(nBlobs))
-		blob = blob.Mul(blob, u256.NewInt(blobPrice))
-		deposit := gas.Add(gas, blob).Div(gas, u256.NewInt(2))
+		quote := Quote{Slot: slot, GasFee: gasPrice, BlobGasFee: blobPrice}
+		deposit, tip, err := m.pricing.Price(tx, quote, failures)
+		if err != nil {
+			m.l.Warn("Pricing reservation failed. Retrying...", "slot", slot, "err", err)
+			retry.fail(Attempt{Stage: StagePrice, Slot: slot, Err: fmt.Errorf("failed to price reservation: %w", err)})
+			continue
+		}
 
 		reserveReq := luban.ReserveBlockSpaceRequest{
 			BlobCount:  nBlobs,
 			Deposit:    hexutil.U256(*deposit),
 			GasLimit:   tx.Gas(),
 			TargetSlot: slot,
-			// Tip is actually the same as deposit
-			Tip: hexutil.U256(*deposit),
+			Tip:        hexutil.U256(*tip),
 		}
-		id, err := m.client.ReserveBlockspace(ctx, reserveReq)
+		reservation, err = m.client.ReserveBlockspace(ctx, reserveReq)
 		if err != nil {
-			m.l.Warn(
-				"Reserving blockspace for tx failed. Someone probably took our slot. Retrying...",
-				"err", err,
-			)
+			// Quote of the slot is likely stale, so it's fetched again
+			m.fees.Invalidate(slot)
+		}
+		if errors.Is(err, client.ErrBlockspaceUnavailable) {
+			m.l.Warn("Someone took our slot. Retrying...", "slot", slot, "err", err)
+			retry.fail(Attempt{Stage: StageReserve, Slot: slot, Err: err})
+			continue
+		} else if errors.Is(err, client.ErrInvalidSignature) {
+			return nil, fmt.Errorf("Gateway rejected blockspace reservation: %w", err)
+		} else if err != nil {
+			m.l.Warn("Reserving blockspace for tx failed. Retrying...", "err", err)
+			retry.fail(Attempt{Stage: StageReserve, Slot: slot, Err: err})
 			continue
 		}
 
-		m.l.Debug("Reserved blockspace", "req", reserveReq)
+		id := reservation.Id
+		m.l.Debug("Reserved blockspace", "id", id, "req", reserveReq)
 
-		err = m.client.SubmitTransaction(ctx, id, tx)
-		if err != nil {
-			m.l.Error("Sending preconfed tx failed. Slashing preconfer...", "err", err)
-			// TODO: slash preconfer
+		// Slot is checked against the slot clock below, so beacon node isn't
+		// polled before every submission
+		res.Reservation = reservation
+		if err := reservation.Validate(ctx, tx, nil); err != nil {
+			return nil, fmt.Errorf("Transaction doesn't fit reserved blockspace: %w", err)
+		}
+		if m.slots != nil && m.slots.CurrentSlot() >= slot {
+			m.l.Warn("Reserved slot started before submission. Retrying...", "id", id, "slot", slot)
+			retry.fail(Attempt{Stage: StageSubmit, Slot: slot, RequestId: id, Err: fmt.Errorf("%w: slot %d started", client.ErrSlotPassed, slot)})
 			continue
 		}
+
+		submitted = true
+		c, err := m.client.SubmitTransaction(ctx, id, tx)
+		if errors.Is(err, client.ErrAlreadySubmitted) {
+			// Previous submission went through, but we lost the response and
+			// gateway has no endpoint to return the commitment again
+			m.l.Warn("Preconfed tx was already submitted, its commitment is missing", "id", id, "err", err)
+			commitment = nil
+			break
+		} else if err != nil {
+			m.l.Error("Sending preconfed tx failed. Slashing preconfer...", "id", id, "err", err)
+			m.collectSubmissionEvidence(ctx, reservation, tx, err)
+			retry.fail(Attempt{Stage: StageSubmit, Slot: slot, RequestId: id, Err: err})
+			continue
+		}
+		m.l.Debug("Got commitment", "id", id, "r", c.R, "s", c.S, "v", c.V)
+		commitment = &c
 		break
 	}
 
-	head, err := m.getHeadSlot()
-	getHeadErr := fmt.Errorf("Failed getting head, while waiting for preconf to fire: %w", err)
+	res.Reservation = reservation
+	res.Receipt, res.Outcome, err = m.waitForInclusion(ctx, reservation, commitment, tx)
+	if errors.Is(err, ErrCommitmentNotHonored) && m.fallback.OnNotHonored {
+		return m.sendToMempool(ctx, tx, res, err.Error())
+	} else if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (m *PreconfTxMgr) From() common.Address {
+	return m.cfg.From
+}
+
+func (m *PreconfTxMgr) BlockNumber(ctx context.Context) (uint64, error) {
+	return m.backend.BlockNumber(ctx)
+}
+
+func (m *PreconfTxMgr) API() rpc.API {
+	return rpc.API{
+		Namespace: "txmgr",
+		Service: &PreconfTxmgrAPI{
+			mgr: m,
+			l:   m.l,
+		},
+	}
+}
+
+// Close closes the underlying connection, and sets the closed flag.
+// once closed, the tx manager will refuse to send any new transactions, and may abandon pending ones.
+func (m *PreconfTxMgr) Close() {
+	m.backend.Close()
+	m.closed.Store(true)
+}
+
+func (m *PreconfTxMgr) IsClosed() bool {
+	return m.closed.Load()
+}
+
+// SuggestGasPriceCaps suggests L1 tip, base fee and blob base fee with
+// configured minimums applied. Base fee is the one of the head block, while
+// blob base fee is computed for the next block with the blob schedule, as
+// transactions are crafted. Blob base fee is nil before Cancun.
+func (m *PreconfTxMgr) SuggestGasPriceCaps(ctx context.Context) (*big.Int, *big.Int, *big.Int, error) {
+	config, err := m.chainConfig()
 	if err != nil {
-		return nil, getHeadErr
+		return nil, nil, nil, fmt.Errorf("failed to get chain config: %w", err)
 	}
+	cCtx, cancel := context.WithTimeout(ctx, m.cfg.NetworkTimeout)
+	defer cancel()
 
-	m.l.Debug("Waiting for preconf", "slot", slot, "head", head)
+	tip, err := m.backend.SuggestGasTipCap(cCtx)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to suggest tip: %w", err)
+	}
+	head, err := m.backend.HeaderByNumber(cCtx, nil)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to get head header: %w", err)
+	}
+	if head.BaseFee == nil {
+		return nil, nil, nil, errors.New("txmgr does not support pre-london blocks that do not have a base fee")
+	}
+	clock, err := m.slotClock(ctx)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to get slot clock: %w", err)
+	}
+	baseFee := head.BaseFee
+	_, blobFee := calcBaseFees(config, m.blobs, head, blockTimes(clock, head, 0)[0])
 
-	for head < slot+1 {
-		time.Sleep(time.Second)
-		head, err = m.getHeadSlot()
-		if err != nil {
-			return nil, getHeadErr
-		}
+	// Enforce minimum base fee and tip cap
+	if minTipCap := m.cfg.MinTipCap.Load(); minTipCap != nil && tip.Cmp(minTipCap) == -1 {
+		m.l.Debug("Enforcing min tip cap", "minTipCap", minTipCap, "origTipCap", tip)
+		tip = new(big.Int).Set(minTipCap)
+	}
+	if minBaseFee := m.cfg.MinBaseFee.Load(); minBaseFee != nil && baseFee.Cmp(minBaseFee) == -1 {
+		m.l.Debug("Enforcing min base fee", "minBaseFee", minBaseFee, "origBaseFee", baseFee)
+		baseFee = new(big.Int).Set(minBaseFee)
 	}
 
-	// TODO: Get err once there is an endpoint in case no receipt
-	return m.backend.TransactionReceipt(ctx, tx.Hash())
+	return tip, baseFee, blobFee, nil
 }
 
-func (m *PreconfTxMgr) getBaseFees(ctx context.Context) (*big.Int, *big.Int, error) {
+// getFeeCaps returns tip, fee cap and blob fee cap for the next block
+// according to the fee policy. Base fees are computed with fork rules active
+// at its time. Blob fee cap is nil before Cancun.
+func (m *PreconfTxMgr) getFeeCaps(ctx context.Context) (*big.Int, *big.Int, *big.Int, error) {
+	config, err := m.chainConfig()
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to get chain config: %w", err)
+	}
 	bn, err := m.backend.BlockNumber(ctx)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to get last block number: %w", err)
+		return nil, nil, nil, fmt.Errorf("failed to get last block number: %w", err)
 	}
 	blk, err := m.backend.BlockByNumber(ctx, big.NewInt(int64(bn)))
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to get latest block (#%d): %w", bn, err)
+		return nil, nil, nil, fmt.Errorf("failed to get latest block (#%d): %w", bn, err)
+	}
+	clock, err := m.slotClock(ctx)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to get slot clock: %w", err)
+	}
+	next := blockTimes(clock, blk.Header(), 0)[0]
+	tip, err := m.suggestTip(ctx)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	baseFee, blobFee := calcBaseFees(config, m.blobs, blk.Header(), next)
+	if minBaseFee := m.cfg.MinBaseFee.Load(); minBaseFee != nil && baseFee.Cmp(minBaseFee) == -1 {
+		m.l.Debug("Enforcing min base fee", "minBaseFee", minBaseFee, "origBaseFee", baseFee)
+		baseFee = new(big.Int).Set(minBaseFee)
 	}
-	// XXX: assume mainnet
-	baseFee := eip1559.CalcBaseFee(params.MainnetChainConfig, blk.Header(), blk.Header().Time+1)
-	blobFee := eip4844.CalcBlobFee(*blk.Header().ExcessBlobGas)
 
-	return baseFee.Mul(baseFee, big.NewInt(2)), blobFee.Mul(blobFee, big.NewInt(2)), nil
+	tip, feeCap, err := m.feePolicy.caps(baseFee, tip, m.cfg.MinTipCap.Load())
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if blobFee == nil {
+		return tip, feeCap, nil, nil
+	}
+	return tip, feeCap, m.feePolicy.blobFeeCap(blobFee, m.cfg.MinBlobTxFee.Load()), nil
 }
 
 // Copied from op-service/txmgr/txmgr.go
 
 // prepare prepares the transaction for sending.
 func (m *PreconfTxMgr) prepare(ctx context.Context, candidate txmgr.TxCandidate) (*types.Transaction, error) {
+	// Unknown chain doesn't get known by retrying
+	if _, err := m.chainConfig(); err != nil {
+		return nil, fmt.Errorf("failed to create the tx: %w", err)
+	}
 	tx, err := retry.Do(ctx, 30, retry.Fixed(2*time.Second), func() (*types.Transaction, error) {
 		tx, err := m.craftTx(ctx, candidate)
 		if err != nil {
@@ -298,26 +586,31 @@ func (m *PreconfTxMgr) craftTx(ctx context.Context, candidate txmgr.TxCandidate)
 		gasLimit = gas
 	}
 
-	baseFee, blobFee, err := m.getBaseFees(ctx)
+	tip, feeCap, blobFeeCap, err := m.getFeeCaps(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get base fees: %w", err)
+		return nil, fmt.Errorf("failed to get fee caps: %w", err)
 	}
 
 	var txMessage types.TxData
 	if sidecar != nil {
+		if blobFeeCap == nil {
+			return nil, ErrBlobsBeforeCancun
+		}
 		txMessage = &types.BlobTx{
 			To:         *candidate.To,
 			Data:       candidate.TxData,
 			Gas:        gasLimit,
-			GasFeeCap:  u256.MustFromBig(baseFee),
-			BlobFeeCap: u256.MustFromBig(blobFee),
+			GasTipCap:  u256.MustFromBig(tip),
+			GasFeeCap:  u256.MustFromBig(feeCap),
+			BlobFeeCap: u256.MustFromBig(blobFeeCap),
 			BlobHashes: blobHashes,
 			Sidecar:    sidecar,
 		}
 	} else {
 		txMessage = &types.DynamicFeeTx{
 			To:        candidate.To,
-			GasFeeCap: baseFee,
+			GasTipCap: tip,
+			GasFeeCap: feeCap,
 			Value:     candidate.Value,
 			Data:      candidate.TxData,
 			Gas:       gasLimit,
@@ -326,6 +619,14 @@ func (m *PreconfTxMgr) craftTx(ctx context.Context, candidate txmgr.TxCandidate)
 	return m.signWithNextNonce(ctx, txMessage) // signer sets the nonce field of the tx
 }
 
+// resetNonce resets the internal nonce tracking. This is called if any pending send
+// returns an error.
+func (m *PreconfTxMgr) resetNonce() {
+	m.nonceLock.Lock()
+	defer m.nonceLock.Unlock()
+	m.nonce = nil
+}
+
 // signWithNextNonce returns a signed transaction with the next available nonce.
 // The nonce is fetched once using eth_getTransactionCount with "latest", and
 // then subsequent calls simply increment this number. If the transaction manager
@@ -2,28 +2,1330 @@ package txmgr
 
 import (
 	"context"
+	"errors"
+	"math"
 	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/google/uuid"
+	u256 "github.com/holiman/uint256"
+
+	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/rpc"
 
+	"github.com/ethereum-optimism/optimism/op-service/clock"
 	"github.com/ethereum-optimism/optimism/op-service/eth"
 	"github.com/ethereum-optimism/optimism/op-service/testlog"
 	"github.com/ethereum-optimism/optimism/op-service/txmgr"
 
+	"github.com/risechain/luban-api/beacon"
 	"github.com/risechain/luban-api/client"
+	"github.com/risechain/luban-api/lubantest"
+	"github.com/risechain/luban-api/slashing"
+	luban "github.com/risechain/luban-api/types"
 )
 
+// fakeBackend has a single block and "includes" transactions, once they are
+// submitted to the gateway or broadcast to the mempool.
+type fakeBackend struct {
+	gateway *lubantest.Gateway
+	header  *types.Header
+
+	mu   sync.Mutex
+	sent []*types.Transaction
+	// censor hides receipts of transactions submitted to the gateway
+	censor atomic.Bool
+}
+
+func newFakeBackend(gateway *lubantest.Gateway) *fakeBackend {
+	excessBlobGas := uint64(0)
+	return &fakeBackend{
+		gateway: gateway,
+		header: &types.Header{
+			Number:        big.NewInt(100),
+			Difficulty:    big.NewInt(0),
+			GasLimit:      30_000_000,
+			GasUsed:       15_000_000,
+			BaseFee:       big.NewInt(1_000_000_000),
+			ExcessBlobGas: &excessBlobGas,
+			Time:          1_700_000_000,
+		},
+	}
+}
+
+func (b *fakeBackend) BlockNumber(ctx context.Context) (uint64, error) {
+	return b.header.Number.Uint64(), nil
+}
+
+func (b *fakeBackend) BlockByNumber(ctx context.Context, num *big.Int) (*types.Block, error) {
+	return types.NewBlockWithHeader(b.header), nil
+}
+
+func (b *fakeBackend) HeaderByNumber(ctx context.Context, num *big.Int) (*types.Header, error) {
+	return b.header, nil
+}
+
+func (b *fakeBackend) CallContract(ctx context.Context, msg ethereum.CallMsg, num *big.Int) ([]byte, error) {
+	return nil, nil
+}
+
+func (b *fakeBackend) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	receipt := &types.Receipt{TxHash: txHash, Status: types.ReceiptStatusSuccessful, BlockNumber: b.header.Number, BlockHash: b.header.Hash()}
+	b.mu.Lock()
+	for _, tx := range b.sent {
+		if tx.Hash() == txHash {
+			b.mu.Unlock()
+			return receipt, nil
+		}
+	}
+	b.mu.Unlock()
+
+	if b.censor.Load() {
+		return nil, ethereum.NotFound
+	}
+	for _, r := range b.gateway.Reservations() {
+		if r.Tx != nil && r.Tx.Hash() == txHash {
+			return receipt, nil
+		}
+	}
+	return nil, ethereum.NotFound
+}
+
+func (b *fakeBackend) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.sent = append(b.sent, tx)
+	return nil
+}
+
+func (b *fakeBackend) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	return big.NewInt(1), nil
+}
+
+func (b *fakeBackend) NonceAt(ctx context.Context, account common.Address, num *big.Int) (uint64, error) {
+	return 0, nil
+}
+
+func (b *fakeBackend) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	return 0, nil
+}
+
+func (b *fakeBackend) EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error) {
+	return 21000, nil
+}
+
+func (b *fakeBackend) Close() {}
+
+// fakeBeacon reports head slot, which advances on every request. All slots
+// have the same block, or are missed if it's nil.
+type fakeBeacon struct {
+	mu    sync.Mutex
+	head  uint64
+	block *beacon.Block
+}
+
+func (b *fakeBeacon) HeadSlot(ctx context.Context) (uint64, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	head := b.head
+	b.head++
+	return head, nil
+}
+
+// WaitForHead fast-forwards head to the slot
+func (b *fakeBeacon) WaitForHead(ctx context.Context, slot uint64) (uint64, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.head = max(b.head, slot)
+	return b.head, nil
+}
+
+func (b *fakeBeacon) Genesis(ctx context.Context) (*beacon.Genesis, error) {
+	return &beacon.Genesis{}, nil
+}
+
+func (b *fakeBeacon) Spec(ctx context.Context) (*beacon.Spec, error) {
+	return &beacon.Spec{SecondsPerSlot: 12, SlotsPerEpoch: 32}, nil
+}
+
+func (b *fakeBeacon) BlockBySlot(ctx context.Context, slot uint64) (*beacon.Block, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.block == nil {
+		return nil, beacon.ErrBlockNotFound
+	}
+	block := *b.block
+	block.Slot = slot
+	return &block, nil
+}
+
+// devChainConfig returns config of a devnet, which has all forks up to Cancun
+// active since genesis.
+func devChainConfig(chainId *big.Int) *params.ChainConfig {
+	config := *params.AllDevChainProtocolChanges
+	config.ChainID = chainId
+	return &config
+}
+
+// newSlotClock returns clock of 12s slots
+func newSlotClock(t *testing.T, genesis time.Time, clk clock.Clock) *beacon.SlotClock {
+	slots, err := beacon.NewSlotClock(genesis, 12*time.Second, clk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return slots
+}
+
+func newTestTxMgr(t *testing.T, opts ...Option) (*PreconfTxMgr, *lubantest.Gateway, common.Address) {
+	gatewayKey, _ := crypto.GenerateKey()
+	gateway := lubantest.NewGateway(gatewayKey)
+	t.Cleanup(gateway.Close)
+
+	key, _ := crypto.GenerateKey()
+	addr := crypto.PubkeyToAddress(key.PublicKey)
+	chainId := big.NewInt(7028081469)
+	cfg := &txmgr.Config{
+		From:           addr,
+		NetworkTimeout: time.Second,
+		Signer: func(ctx context.Context, from common.Address, tx *types.Transaction) (*types.Transaction, error) {
+			return types.SignTx(tx, types.LatestSignerForChainID(chainId), key)
+		},
+	}
+	preconfer, err := client.NewClient(gateway.URL, client.NewPrivateKeySigner(key))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l := testlog.Logger(t, log.LevelDebug)
+	opts = append([]Option{WithChainConfig(devChainConfig(chainId))}, opts...)
+	return NewPreconfTxMgr(l, newFakeBackend(gateway), cfg, preconfer, &fakeBeacon{head: 1}, opts...), gateway, addr
+}
+
+func TestSendMock(t *testing.T) {
+	txmanager, gateway, addr := newTestTxMgr(t)
+	for slot := uint64(2); slot < 8; slot++ {
+		gateway.AddSlot(luban.SlotInfo{Slot: slot, GasAvailable: 30_000_000, BlobsAvailable: 6})
+	}
+	gateway.SetDefaultFee(lubantest.Fee{GasFee: 10, BlobGasFee: 10})
+	gateway.RaceNextReservation()
+
+	cand := txmgr.TxCandidate{To: &addr, GasLimit: 21000}
+	receipt, err := txmanager.Send(context.Background(), cand)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if receipt == nil {
+		t.Fatal("No receipt for preconfed tx")
+	}
+
+	reservations := gateway.Reservations()
+	if len(reservations) != 1 {
+		t.Fatalf("Expected single reservation, have %d", len(reservations))
+	}
+	// First attempt targets slot 3, which gets raced
+	if have := reservations[0].Request.TargetSlot; have <= 3 {
+		t.Fatalf("Reserved wrong slot. Have %d, want >3", have)
+	}
+	if have := gateway.Requests(lubantest.EndpointReserve); have != 2 {
+		t.Fatalf("Expected reservation to be retried once after race, have %d requests", have)
+	}
+}
+
+func TestPricingStrategies(t *testing.T) {
+	tx := types.NewTx(&types.DynamicFeeTx{Gas: 21000})
+	quote := Quote{Slot: 3, GasFee: 10, BlobGasFee: 10}
+	for _, test := range []struct {
+		name     string
+		strategy PricingStrategy
+		attempt  int
+		deposit  uint64
+		tip      uint64
+		err      error
+	}{
+		{name: "spec", strategy: SpecPricing{}, deposit: 105000, tip: 105000},
+		{name: "tip multiplier", strategy: TipMultiplierPricing{Percent: 150}, deposit: 105000, tip: 157500},
+		{name: "tip multiplier zero", strategy: TipMultiplierPricing{}, deposit: 105000, tip: 105000},
+		{name: "escalating first", strategy: EscalatingTipPricing{StepPercent: 20}, deposit: 105000, tip: 105000},
+		{name: "escalating third", strategy: EscalatingTipPricing{StepPercent: 20}, attempt: 2, deposit: 105000, tip: 147000},
+		{name: "within budget", strategy: BudgetPricing{Strategy: SpecPricing{}, Budget: u256.NewInt(210000)}, deposit: 105000, tip: 105000},
+		{name: "over budget", strategy: BudgetPricing{Strategy: SpecPricing{}, Budget: u256.NewInt(209999)}, err: ErrOverBudget},
+		{name: "no budget", strategy: BudgetPricing{Strategy: SpecPricing{}}, err: ErrNoBudget},
+		{name: "no strategy", strategy: BudgetPricing{Budget: u256.NewInt(210000)}, deposit: 105000, tip: 105000},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			deposit, tip, err := test.strategy.Price(tx, quote, test.attempt)
+			if test.err != nil {
+				if !errors.Is(err, test.err) {
+					t.Fatalf("Expected %v, have %v", test.err, err)
+				}
+				return
+			} else if err != nil {
+				t.Fatal(err)
+			}
+			if deposit.Uint64() != test.deposit || tip.Uint64() != test.tip {
+				t.Fatalf("Wrong price. Have %v/%v, want %v/%v", deposit, tip, test.deposit, test.tip)
+			}
+		})
+	}
+}
+
+func TestSendEscalatingTip(t *testing.T) {
+	txmanager, gateway, addr := newTestTxMgr(t, WithPricingStrategy(EscalatingTipPricing{StepPercent: 50}))
+	for slot := uint64(2); slot < 8; slot++ {
+		gateway.AddSlot(luban.SlotInfo{Slot: slot, GasAvailable: 30_000_000, BlobsAvailable: 6})
+	}
+	gateway.SetDefaultFee(lubantest.Fee{GasFee: 10, BlobGasFee: 10})
+	gateway.FailNext(lubantest.EndpointReserve, http.StatusBadRequest, lubantest.MsgBlockspaceUnavailable)
+
+	if _, err := txmanager.Send(context.Background(), txmgr.TxCandidate{To: &addr, GasLimit: 21000}); err != nil {
+		t.Fatal(err)
+	}
+	req := gateway.Reservations()[0].Request
+	if deposit, tip := (*u256.Int)(&req.Deposit), (*u256.Int)(&req.Tip); deposit.Uint64() != 105000 || tip.Uint64() != 157500 {
+		t.Fatalf("Tip wasn't escalated on retry. Have deposit %v, tip %v", deposit, tip)
+	}
+}
+
+func TestSlotSelectors(t *testing.T) {
+	noConstraints := uint32(0)
+	genesis := time.Unix(1_700_000_000, 0)
+	for _, test := range []struct {
+		name     string
+		selector SlotSelector
+		slot     uint64
+	}{
+		{name: "earliest", selector: EarliestSlot{}, slot: 4},
+		{name: "cheapest", selector: CheapestSlot{}, slot: 6},
+		{name: "cheapest in window", selector: CheapestSlot{Window: 2}, slot: 5},
+		{name: "headroom", selector: HeadroomSlot{}, slot: 7},
+		{name: "deadline", selector: DeadlineSlot{MaxDelay: 5 * 12 * time.Second, Then: CheapestSlot{}}, slot: 5},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			// Current slot is 1, so slots from 3 can be reserved
+			clk := clock.NewDeterministicClock(genesis.Add(12 * time.Second))
+			txmanager, gateway, addr := newTestTxMgr(t, WithSlotClock(newSlotClock(t, genesis, clk)), WithSlotSelector(test.selector))
+			gateway.AddSlot(luban.SlotInfo{Slot: 2, GasAvailable: 30_000_000, BlobsAvailable: 6})
+			gateway.AddSlot(luban.SlotInfo{Slot: 3, GasAvailable: 30_000_000, BlobsAvailable: 6, ConstraintsAvailable: &noConstraints})
+			gateway.AddSlot(luban.SlotInfo{Slot: 4, GasAvailable: 10_000_000, BlobsAvailable: 6})
+			gateway.AddSlot(luban.SlotInfo{Slot: 5, GasAvailable: 10_000_000, BlobsAvailable: 6})
+			gateway.AddSlot(luban.SlotInfo{Slot: 6, GasAvailable: 10_000_000, BlobsAvailable: 6})
+			gateway.AddSlot(luban.SlotInfo{Slot: 7, GasAvailable: 20_000_000, BlobsAvailable: 6})
+			gateway.SetDefaultFee(lubantest.Fee{GasFee: 10, BlobGasFee: 10})
+			gateway.SetFee(3, lubantest.Fee{GasFee: 1, BlobGasFee: 1})
+			gateway.SetFee(5, lubantest.Fee{GasFee: 8, BlobGasFee: 8})
+			gateway.SetFee(6, lubantest.Fee{GasFee: 5, BlobGasFee: 5})
+
+			if _, err := txmanager.Send(context.Background(), txmgr.TxCandidate{To: &addr, GasLimit: 21000}); err != nil {
+				t.Fatal(err)
+			}
+			if have := gateway.Reservations()[0].Request.TargetSlot; have != test.slot {
+				t.Fatalf("Reserved wrong slot. Have %d, want %d", have, test.slot)
+			}
+		})
+	}
+}
+
+func TestDeadlineSlotNoSlots(t *testing.T) {
+	genesis := time.Unix(1_700_000_000, 0)
+	clk := clock.NewDeterministicClock(genesis.Add(12 * time.Second))
+	txmanager, gateway, addr := newTestTxMgr(t, WithSlotClock(newSlotClock(t, genesis, clk)), WithSlotSelector(DeadlineSlot{MaxDelay: time.Minute}))
+	gateway.AddSlot(luban.SlotInfo{Slot: 10, GasAvailable: 30_000_000, BlobsAvailable: 6})
+
+	_, err := txmanager.Send(context.Background(), txmgr.TxCandidate{To: &addr, GasLimit: 21000})
+	if !errors.Is(err, ErrNoSlotsAvailable) {
+		t.Fatalf("Expected %v, have %v", ErrNoSlotsAvailable, err)
+	}
+}
+
+func TestSendWithSlotClock(t *testing.T) {
+	genesis := time.Unix(1_700_000_000, 0)
+	clk := clock.NewDeterministicClock(genesis.Add(5 * 12 * time.Second))
+	txmanager, gateway, addr := newTestTxMgr(t, WithSlotClock(newSlotClock(t, genesis, clk)))
+	for slot := uint64(2); slot < 10; slot++ {
+		gateway.AddSlot(luban.SlotInfo{Slot: slot, GasAvailable: 30_000_000, BlobsAvailable: 6})
+	}
+	gateway.SetDefaultFee(lubantest.Fee{GasFee: 10, BlobGasFee: 10})
+
+	if _, err := txmanager.Send(context.Background(), txmgr.TxCandidate{To: &addr, GasLimit: 21000}); err != nil {
+		t.Fatal(err)
+	}
+	// Current slot is 5, so the first slot far enough is 7, while beacon
+	// head is still 1
+	if have := gateway.Reservations()[0].Request.TargetSlot; have != 7 {
+		t.Fatalf("Reserved wrong slot. Have %d, want 7", have)
+	}
+}
+
+func TestSendNotHonored(t *testing.T) {
+	txmanager, gateway, addr := newTestTxMgr(t, WithInclusionConfig(InclusionConfig{
+		Timeout: 5 * time.Second,
+		Grace:   50 * time.Millisecond,
+	}))
+	txmanager.cfg.ReceiptQueryInterval = 10 * time.Millisecond
+	txmanager.backend.(*fakeBackend).censor.Store(true)
+	txmanager.beacon.(*fakeBeacon).block = &beacon.Block{BlockNumber: 100}
+	for slot := uint64(2); slot < 8; slot++ {
+		gateway.AddSlot(luban.SlotInfo{Slot: slot, GasAvailable: 30_000_000, BlobsAvailable: 6})
+	}
+	gateway.SetDefaultFee(lubantest.Fee{GasFee: 10, BlobGasFee: 10})
+
+	tx := txmgr.TxCandidate{To: &addr, GasLimit: 21000}
+	_, err := txmanager.Send(context.Background(), tx)
+	if !errors.Is(err, ErrCommitmentNotHonored) {
+		t.Fatalf("Expected %v, have %v", ErrCommitmentNotHonored, err)
+	}
+	var notHonored *CommitmentNotHonoredError
+	if !errors.As(err, &notHonored) {
+		t.Fatalf("Expected %T, have %T", notHonored, err)
+	}
+	reservation := gateway.Reservations()[0]
+	if notHonored.Slot != reservation.Request.TargetSlot || notHonored.RequestId != reservation.Id || notHonored.TxHash != reservation.Tx.Hash() || notHonored.Outcome != NotIncluded {
+		t.Fatalf("Wrong commitment in error: %+v", notHonored)
+	}
+}
+
+func TestSendFallback(t *testing.T) {
+	addSlots := func(gateway *lubantest.Gateway) {
+		for slot := uint64(2); slot < 8; slot++ {
+			gateway.AddSlot(luban.SlotInfo{Slot: slot, GasAvailable: 30_000_000, BlobsAvailable: 6})
+		}
+	}
+	for _, test := range []struct {
+		name     string
+		policy   FallbackPolicy
+		setup    func(*PreconfTxMgr, *lubantest.Gateway)
+		path     SendPath
+		reason   string
+		reserved int
+	}{
+		{
+			name:   "no slots",
+			policy: FallbackPolicy{OnNoSlots: true},
+			setup:  func(m *PreconfTxMgr, g *lubantest.Gateway) {},
+			path:   PathMempool,
+			reason: "no slots available",
+		},
+		{
+			name:   "max failures",
+			policy: FallbackPolicy{MaxFailures: 2},
+			setup: func(m *PreconfTxMgr, g *lubantest.Gateway) {
+				addSlots(g)
+				g.FailNext(lubantest.EndpointReserve, http.StatusInternalServerError, lubantest.MsgInternal)
+				g.FailNext(lubantest.EndpointReserve, http.StatusInternalServerError, lubantest.MsgInternal)
+			},
+			path:   PathMempool,
+			reason: "2 preconf attempts failed",
+		},
+		{
+			name:   "quote and reservation rejected",
+			policy: FallbackPolicy{MaxFailures: 2},
+			setup: func(m *PreconfTxMgr, g *lubantest.Gateway) {
+				addSlots(g)
+				g.FailNext(lubantest.EndpointFee, http.StatusInternalServerError, lubantest.MsgInternal)
+				g.FailNext(lubantest.EndpointReserve, http.StatusBadRequest, "bad request")
+			},
+			path:   PathMempool,
+			reason: "2 preconf attempts failed",
+		},
+		{
+			name:   "retries exhausted before max failures",
+			policy: FallbackPolicy{MaxFailures: 11},
+			setup: func(m *PreconfTxMgr, g *lubantest.Gateway) {
+				m.retry.MinBackoff = time.Millisecond
+				m.retry.MaxBackoff = 5 * time.Millisecond
+				addSlots(g)
+				for range m.retry.MaxAttempts {
+					g.FailNext(lubantest.EndpointReserve, http.StatusInternalServerError, lubantest.MsgInternal)
+				}
+			},
+			path:   PathMempool,
+			reason: ErrRetriesExhausted.Error(),
+		},
+		{
+			name:   "recovered before max failures",
+			policy: FallbackPolicy{MaxFailures: 2},
+			setup: func(m *PreconfTxMgr, g *lubantest.Gateway) {
+				addSlots(g)
+				g.FailNext(lubantest.EndpointReserve, http.StatusInternalServerError, lubantest.MsgInternal)
+			},
+			path:     PathPreconf,
+			reserved: 1,
+		},
+		{
+			name:   "not honored",
+			policy: FallbackPolicy{OnNotHonored: true},
+			setup: func(m *PreconfTxMgr, g *lubantest.Gateway) {
+				addSlots(g)
+				m.backend.(*fakeBackend).censor.Store(true)
+				m.beacon.(*fakeBeacon).block = &beacon.Block{BlockNumber: 100}
+			},
+			path:     PathMempool,
+			reason:   ErrCommitmentNotHonored.Error(),
+			reserved: 1,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			txmanager, gateway, addr := newTestTxMgr(t, WithFallback(test.policy), WithInclusionConfig(InclusionConfig{
+				Timeout: 5 * time.Second,
+				Grace:   50 * time.Millisecond,
+			}))
+			txmanager.cfg.ReceiptQueryInterval = 10 * time.Millisecond
+			gateway.SetDefaultFee(lubantest.Fee{GasFee: 10, BlobGasFee: 10})
+			test.setup(txmanager, gateway)
+
+			res, err := txmanager.SendWithResult(context.Background(), txmgr.TxCandidate{To: &addr, GasLimit: 21000})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.Path != test.path || !strings.HasPrefix(res.FallbackReason, test.reason) {
+				t.Fatalf("Wrong path. Have %v (%q), want %v (%q)", res.Path, res.FallbackReason, test.path, test.reason)
+			}
+			if res.Receipt == nil {
+				t.Fatal("No receipt")
+			}
+			if have := len(gateway.Reservations()); have != test.reserved {
+				t.Fatalf("Wrong number of reservations. Have %d, want %d", have, test.reserved)
+			}
+			sent := txmanager.backend.(*fakeBackend).sent
+			if test.path == PathMempool && (len(sent) != 1 || sent[0].Hash() != res.Receipt.TxHash) {
+				t.Fatal("Tx wasn't broadcast to mempool")
+			} else if test.path == PathPreconf && (len(sent) != 0 || res.Reservation == nil) {
+				t.Fatalf("Tx wasn't preconfed: %+v", res)
+			}
+		})
+	}
+}
+
+func TestSendNoFallback(t *testing.T) {
+	txmanager, _, addr := newTestTxMgr(t)
+	_, err := txmanager.Send(context.Background(), txmgr.TxCandidate{To: &addr, GasLimit: 21000})
+	if !errors.Is(err, ErrNoSlotsAvailable) {
+		t.Fatalf("Expected %v, have %v", ErrNoSlotsAvailable, err)
+	}
+	if sent := txmanager.backend.(*fakeBackend).sent; len(sent) != 0 {
+		t.Fatal("Tx was broadcast to mempool without fallback policy")
+	}
+}
+
+func TestSendRetriesSlots(t *testing.T) {
+	// Default retry policy retries failure to get slots without fallback
+	txmanager, gateway, addr := newTestTxMgr(t)
+	gateway.AddSlot(luban.SlotInfo{Slot: 6, GasAvailable: 30_000_000, BlobsAvailable: 6})
+	gateway.FailNext(lubantest.EndpointSlots, http.StatusInternalServerError, lubantest.MsgInternal)
+
+	if _, err := txmanager.Send(context.Background(), txmgr.TxCandidate{To: &addr, GasLimit: 21000}); err != nil {
+		t.Fatal(err)
+	}
+	if have := gateway.Requests(lubantest.EndpointSlots); have < 2 {
+		t.Fatalf("Getting slots wasn't retried. Have %d requests", have)
+	}
+}
+
+func TestSendRetriesExhausted(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		exclude bool
+		slots   []uint64
+	}{
+		{name: "exclude failed slots", exclude: true, slots: []uint64{3, 4, 5}},
+		{name: "retry failed slots", exclude: false, slots: []uint64{3, 3, 3}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			// Current slot stays 1, so slot 3 remains the earliest one
+			genesis := time.Unix(1_700_000_000, 0)
+			clk := clock.NewDeterministicClock(genesis.Add(12 * time.Second))
+			txmanager, gateway, addr := newTestTxMgr(t, WithSlotClock(newSlotClock(t, genesis, clk)), WithRetryPolicy(RetryPolicy{
+				MaxAttempts:        3,
+				MinBackoff:         20 * time.Millisecond,
+				MaxBackoff:         30 * time.Millisecond,
+				ExcludeFailedSlots: test.exclude,
+			}))
+			for slot := uint64(2); slot < 8; slot++ {
+				gateway.AddSlot(luban.SlotInfo{Slot: slot, GasAvailable: 30_000_000, BlobsAvailable: 6})
+			}
+			gateway.SetDefaultFee(lubantest.Fee{GasFee: 10, BlobGasFee: 10})
+			for range 3 {
+				gateway.FailNext(lubantest.EndpointReserve, http.StatusInternalServerError, lubantest.MsgInternal)
+			}
+
+			start := time.Now()
+			_, err := txmanager.Send(context.Background(), txmgr.TxCandidate{To: &addr, GasLimit: 21000})
+			var exhausted *RetriesExhaustedError
+			if !errors.Is(err, ErrRetriesExhausted) || !errors.As(err, &exhausted) {
+				t.Fatalf("Expected %v, have %v", ErrRetriesExhausted, err)
+			}
+			// Backoff is 20ms after the first failure and 30ms after the second
+			if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+				t.Fatalf("Retried without backoff in %v", elapsed)
+			}
+			if len(exhausted.Attempts) != len(test.slots) {
+				t.Fatalf("Wrong number of attempts. Have %d, want %d: %v", len(exhausted.Attempts), len(test.slots), err)
+			}
+			for i, a := range exhausted.Attempts {
+				if a.Stage != StageReserve || a.Slot != test.slots[i] || a.Err == nil {
+					t.Fatalf("Wrong attempt #%d: %v", i+1, a)
+				}
+			}
+			if have := gateway.Requests(lubantest.EndpointReserve); have != 3 {
+				t.Fatalf("Expected 3 reservation requests, have %d", have)
+			}
+		})
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	uncapped := newRetryState(RetryPolicy{MinBackoff: 10 * time.Millisecond})
+	for _, want := range []time.Duration{10 * time.Millisecond, 20 * time.Millisecond, 40 * time.Millisecond} {
+		if have := uncapped.wait(); have != want {
+			t.Fatalf("Wrong uncapped backoff. Have %v, want %v", have, want)
+		}
+	}
+
+	capped := newRetryState(RetryPolicy{MinBackoff: 10 * time.Millisecond, MaxBackoff: 15 * time.Millisecond})
+	for _, want := range []time.Duration{10 * time.Millisecond, 15 * time.Millisecond, 15 * time.Millisecond} {
+		if have := capped.wait(); have != want {
+			t.Fatalf("Wrong capped backoff. Have %v, want %v", have, want)
+		}
+	}
+
+	deadline := newRetryState(RetryPolicy{MinBackoff: time.Minute, Deadline: 100 * time.Millisecond})
+	if have := deadline.wait(); have > 100*time.Millisecond {
+		t.Fatalf("Backoff %v outlasts deadline", have)
+	}
+	deadline.start = deadline.start.Add(-time.Second)
+	if have := deadline.wait(); have != 0 {
+		t.Fatalf("Backoff %v after deadline", have)
+	}
+}
+
+func TestSendRetryAllSlotsExcluded(t *testing.T) {
+	txmanager, gateway, addr := newTestTxMgr(t, WithRetryPolicy(RetryPolicy{
+		MaxAttempts:        3,
+		ExcludeFailedSlots: true,
+	}))
+	gateway.AddSlot(luban.SlotInfo{Slot: 3, GasAvailable: 30_000_000, BlobsAvailable: 6})
+	gateway.SetDefaultFee(lubantest.Fee{GasFee: 10, BlobGasFee: 10})
+	gateway.RaceNextReservation()
+
+	_, err := txmanager.Send(context.Background(), txmgr.TxCandidate{To: &addr, GasLimit: 21000})
+	var exhausted *RetriesExhaustedError
+	if !errors.As(err, &exhausted) {
+		t.Fatalf("Expected %v, have %v", ErrRetriesExhausted, err)
+	}
+	if !errors.Is(err, client.ErrBlockspaceUnavailable) || !errors.Is(err, ErrNoSlotsAvailable) {
+		t.Fatalf("Attempt errors aren't wrapped: %v", err)
+	}
+	if have := gateway.Requests(lubantest.EndpointReserve); have != 1 {
+		t.Fatalf("Excluded slot was reserved again, have %d requests", have)
+	}
+}
+
+func TestSendInvalidatesQuote(t *testing.T) {
+	txmanager, gateway, addr := newTestTxMgr(t, WithRetryPolicy(RetryPolicy{ExcludeFailedSlots: false}))
+	gateway.AddSlot(luban.SlotInfo{Slot: 10, GasAvailable: 30_000_000, BlobsAvailable: 6})
+	gateway.SetDefaultFee(lubantest.Fee{GasFee: 10, BlobGasFee: 10})
+	gateway.FailNext(lubantest.EndpointReserve, http.StatusInternalServerError, lubantest.MsgInternal)
+	gateway.FailNext(lubantest.EndpointReserve, http.StatusInternalServerError, lubantest.MsgInternal)
+
+	if _, err := txmanager.Send(context.Background(), txmgr.TxCandidate{To: &addr, GasLimit: 21000}); err != nil {
+		t.Fatal(err)
+	}
+	// Quote of the slot is fetched again after every failed reservation
+	if have := gateway.Requests(lubantest.EndpointFee); have != 3 {
+		t.Fatalf("Expected 3 fee requests, have %d", have)
+	}
+}
+
+func TestInclusionOutcome(t *testing.T) {
+	included := newFakeBackend(nil).header.Hash()
+	for _, test := range []struct {
+		block   *beacon.Block
+		outcome InclusionOutcome
+	}{
+		{block: &beacon.Block{BlockNumber: 100, BlockHash: included}, outcome: IncludedOnTime},
+		{block: &beacon.Block{BlockNumber: 99, BlockHash: common.Hash{1}}, outcome: IncludedLate},
+		{block: nil, outcome: SlotMissed},
+	} {
+		t.Run(test.outcome.String(), func(t *testing.T) {
+			var reports []InclusionReport
+			txmanager, gateway, addr := newTestTxMgr(t, WithInclusionReporter(func(r InclusionReport) {
+				reports = append(reports, r)
+			}))
+			txmanager.beacon.(*fakeBeacon).block = test.block
+			gateway.AddSlot(luban.SlotInfo{Slot: 3, GasAvailable: 30_000_000, BlobsAvailable: 6})
+
+			receipt, err := txmanager.Send(context.Background(), txmgr.TxCandidate{To: &addr, GasLimit: 21000})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(reports) != 1 {
+				t.Fatalf("Expected single report, have %d", len(reports))
+			}
+			report := reports[0]
+			if report.Outcome != test.outcome {
+				t.Fatalf("Wrong outcome. Have %v, want %v", report.Outcome, test.outcome)
+			}
+			if report.Receipt != receipt || report.Reservation.TargetSlot() != 3 || report.Tx.Hash() != receipt.TxHash {
+				t.Fatalf("Wrong report: %+v", report)
+			}
+			if err := report.Commitment.Verify(report.Reservation.Id, report.Tx, gateway.Address()); err != nil {
+				t.Fatalf("Report has wrong commitment: %v", err)
+			}
+		})
+	}
+}
+
+type evidenceRecorder struct {
+	mu       sync.Mutex
+	evidence []*slashing.Evidence
+}
+
+func (r *evidenceRecorder) SubmitEvidence(ctx context.Context, ev *slashing.Evidence) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.evidence = append(r.evidence, ev)
+	return nil
+}
+
+// wait returns evidence, once n of it was submitted in the background
+func (r *evidenceRecorder) wait(t *testing.T, n int) []*slashing.Evidence {
+	deadline := time.Now().Add(time.Second)
+	for {
+		r.mu.Lock()
+		evidence := append([]*slashing.Evidence(nil), r.evidence...)
+		r.mu.Unlock()
+		if len(evidence) >= n || time.Now().After(deadline) {
+			return evidence
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestSlashingEvidence(t *testing.T) {
+	store, err := slashing.NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	submitted := &evidenceRecorder{}
+	collector := slashing.NewCollector(testlog.Logger(t, log.LevelDebug), store, submitted)
+	txmanager, gateway, addr := newTestTxMgr(t, WithSlashing(collector), WithInclusionConfig(InclusionConfig{
+		Timeout: 5 * time.Second,
+		Grace:   50 * time.Millisecond,
+	}))
+	txmanager.cfg.ReceiptQueryInterval = 10 * time.Millisecond
+	backend := txmanager.backend.(*fakeBackend)
+	backend.censor.Store(true)
+	txmanager.beacon.(*fakeBeacon).block = &beacon.Block{BlockNumber: 100, BlockHash: backend.header.Hash()}
+	for slot := uint64(2); slot < 8; slot++ {
+		gateway.AddSlot(luban.SlotInfo{Slot: slot, GasAvailable: 30_000_000, BlobsAvailable: 6})
+	}
+	gateway.FailNext(lubantest.EndpointSubmit, http.StatusInternalServerError, lubantest.MsgInternal)
+
+	_, err = txmanager.Send(context.Background(), txmgr.TxCandidate{To: &addr, GasLimit: 21000})
+	if !errors.Is(err, ErrCommitmentNotHonored) {
+		t.Fatalf("Expected %v, have %v", ErrCommitmentNotHonored, err)
+	}
+
+	evidence := submitted.wait(t, 2)
+	if len(evidence) != 2 {
+		t.Fatalf("Expected evidence for failed submission and missing tx, have %d", len(evidence))
+	}
+	failed, missing := evidence[0], evidence[1]
+	if failed.Kind != slashing.SubmissionFailed || failed.Commitment != nil || failed.Reason == "" {
+		t.Fatalf("Wrong evidence of failed submission: %+v", failed)
+	}
+	// Without commitment, gateway is recovered from the reservation receipt
+	if !failed.ReservationReceipt.IsSigned() || failed.Gateway == nil || *failed.Gateway != gateway.Address() {
+		t.Fatalf("Evidence of failed submission has wrong gateway: %v", failed.Gateway)
+	}
+	if missing.Kind != slashing.NotIncluded || missing.Commitment == nil || missing.ExecutionBlock == nil || missing.BeaconBlock == nil {
+		t.Fatalf("Wrong evidence of missing tx: %+v", missing)
+	}
+	if missing.Gateway == nil || *missing.Gateway != gateway.Address() {
+		t.Fatalf("Evidence has wrong gateway: %v", missing.Gateway)
+	}
+	if failed.RequestId == missing.RequestId {
+		t.Fatal("Failed submission should be retried with new reservation")
+	}
+	if stored, err := store.List(); err != nil || len(stored) != 2 {
+		t.Fatalf("Evidence wasn't persisted: %v", err)
+	}
+
+	// Failures on our side aren't evidence against the gateway
+	reservation := &client.Reservation{Id: uuid.New(), Request: luban.ReserveBlockSpaceRequest{TargetSlot: 9}}
+	txmanager.collectSubmissionEvidence(context.Background(), reservation, types.NewTx(&types.DynamicFeeTx{}), context.DeadlineExceeded)
+	if stored, err := store.List(); err != nil || len(stored) != 2 {
+		t.Fatalf("Evidence collected for transport failure: %v", err)
+	}
+}
+
+func TestBaseFees(t *testing.T) {
+	prague := uint64(1_700_000_024)
+	config := devChainConfig(big.NewInt(1337))
+	config.PragueTime = &prague
+	preCancun := devChainConfig(big.NewInt(1337))
+	preCancun.CancunTime = nil
+
+	excess, used := uint64(400*params.BlobTxBlobGasPerBlob), uint64(6*params.BlobTxBlobGasPerBlob)
+	parent := &types.Header{
+		Number:        big.NewInt(100),
+		GasLimit:      30_000_000,
+		GasUsed:       15_000_000,
+		BaseFee:       big.NewInt(1_000_000_000),
+		ExcessBlobGas: &excess,
+		BlobGasUsed:   &used,
+		Time:          1_700_000_000,
+	}
+	for _, test := range []struct {
+		name    string
+		config  *params.ChainConfig
+		parent  *types.Header
+		time    uint64
+		blobFee *big.Int
+	}{
+		{
+			name:    "pre-cancun",
+			config:  preCancun,
+			parent:  &types.Header{Number: big.NewInt(100), GasLimit: 30_000_000, GasUsed: 15_000_000, BaseFee: big.NewInt(1_000_000_000), Time: 1_700_000_000},
+			time:    1_700_000_012,
+			blobFee: nil,
+		},
+		{
+			name:    "cancun",
+			config:  config,
+			parent:  parent,
+			time:    1_700_000_012,
+			blobFee: CancunBlobConfig.BlobFee(excess + used - 3*params.BlobTxBlobGasPerBlob),
+		},
+		{
+			name:    "prague",
+			config:  config,
+			parent:  parent,
+			time:    1_700_000_024,
+			blobFee: PragueBlobConfig.BlobFee(excess + used - 6*params.BlobTxBlobGasPerBlob),
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			baseFee, blobFee := calcBaseFees(test.config, DefaultBlobSchedule(), test.parent, test.time)
+			if baseFee.Cmp(big.NewInt(1_000_000_000)) != 0 {
+				t.Fatalf("Wrong base fee %v", baseFee)
+			}
+			if (blobFee == nil) != (test.blobFee == nil) || blobFee != nil && blobFee.Cmp(test.blobFee) != 0 {
+				t.Fatalf("Wrong blob fee. Have %v, want %v", blobFee, test.blobFee)
+			}
+		})
+	}
+
+	// Prague fee with the same excess is lower due to bigger update fraction
+	if PragueBlobConfig.BlobFee(excess).Cmp(CancunBlobConfig.BlobFee(excess)) >= 0 {
+		t.Fatal("Prague blob fee should grow slower")
+	}
+}
+
+func TestProjectFees(t *testing.T) {
+	prague := uint64(1_700_000_024)
+	config := devChainConfig(big.NewInt(1337))
+	config.PragueTime = &prague
+
+	excess, used := uint64(0), uint64(6*params.BlobTxBlobGasPerBlob)
+	parent := &types.Header{
+		Number:        big.NewInt(100),
+		GasLimit:      30_000_000,
+		GasUsed:       15_000_000,
+		BaseFee:       big.NewInt(1_000_000_000),
+		ExcessBlobGas: &excess,
+		BlobGasUsed:   &used,
+		Time:          1_700_000_000,
+	}
+	baseFee, blobFee := projectFees(config, DefaultBlobSchedule(), parent, []uint64{1_700_000_012, 1_700_000_024, 1_700_000_036})
+	// Parent is at target, the following two blocks are full
+	if want := big.NewInt(1_265_625_000); baseFee.Cmp(want) != 0 {
+		t.Fatalf("Wrong base fee. Have %v, want %v", baseFee, want)
+	}
+	// Cancun block with 6 blobs over target of 3, then Prague block with
+	// 9 blobs over target of 6
+	wantExcess := uint64((3 + 0 + 3) * params.BlobTxBlobGasPerBlob)
+	if want := PragueBlobConfig.BlobFee(wantExcess); blobFee.Cmp(want) != 0 {
+		t.Fatalf("Wrong blob fee. Have %v, want %v", blobFee, want)
+	}
+
+	next, nextBlob := calcBaseFees(config, DefaultBlobSchedule(), parent, 1_700_000_012)
+	baseFee, blobFee = projectFees(config, DefaultBlobSchedule(), parent, []uint64{1_700_000_012})
+	if baseFee.Cmp(next) != 0 || blobFee.Cmp(nextBlob) != 0 {
+		t.Fatal("Projection to the next block differs from its fees")
+	}
+}
+
+func TestFeeSafetyMultiplierInvalid(t *testing.T) {
+	for _, multiplier := range []float64{0, 0.5, -1, math.NaN(), math.Inf(1)} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Fatalf("Multiplier %v was accepted", multiplier)
+				}
+			}()
+			WithFeeSafetyMultiplier(multiplier)
+		}()
+	}
+}
+
+func TestSendFeeProjection(t *testing.T) {
+	genesis := time.Unix(1_700_000_000, 0)
+	clk := clock.NewDeterministicClock(genesis.Add(2 * 12 * time.Second))
+	txmanager, gateway, addr := newTestTxMgr(t, WithSlotClock(newSlotClock(t, genesis, clk)), WithFeeSafetyMultiplier(2))
+	gateway.AddSlot(luban.SlotInfo{Slot: 6, GasAvailable: 30_000_000, BlobsAvailable: 6})
+	gateway.SetDefaultFee(lubantest.Fee{GasFee: 10, BlobGasFee: 10})
+
+	if _, err := txmanager.Send(context.Background(), txmgr.TxCandidate{To: &addr, GasLimit: 21000}); err != nil {
+		t.Fatal(err)
+	}
+	// Latest block is at genesis, so there are 6 blocks till the target
+	// slot, all but the first may be full
+	want := new(big.Int).Mul(big.NewInt(1_000_000_000), big.NewInt(9*9*9*9*9))
+	want.Div(want, big.NewInt(8*8*8*8*8))
+	want.Mul(want, big.NewInt(2))
+	want.Add(want, big.NewInt(1)) // tip
+	tx := gateway.Reservations()[0].Tx
+	if tx.GasFeeCap().Cmp(want) != 0 {
+		t.Fatalf("Wrong fee cap. Have %v, want %v", tx.GasFeeCap(), want)
+	}
+	if tx.Nonce() != 0 {
+		t.Fatalf("Re-signed tx has wrong nonce %d", tx.Nonce())
+	}
+}
+
+func TestFeePolicy(t *testing.T) {
+	blob := []*eth.Blob{{}}
+	for _, test := range []struct {
+		name       string
+		policy     FeePolicy
+		setup      func(*txmgr.Config)
+		blobs      []*eth.Blob
+		tip        int64
+		feeCap     int64
+		blobFeeCap int64
+		fails      bool
+	}{
+		{
+			name:   "default",
+			tip:    1,
+			feeCap: 2_000_000_001,
+		},
+		{
+			name: "config minimums",
+			setup: func(cfg *txmgr.Config) {
+				cfg.MinTipCap.Store(big.NewInt(100))
+				cfg.MinBaseFee.Store(big.NewInt(3_000_000_000))
+				cfg.MinBlobTxFee.Store(big.NewInt(50))
+			},
+			blobs:      blob,
+			tip:        100,
+			feeCap:     6_000_000_100,
+			blobFeeCap: 50,
+		},
+		{
+			name:   "fixed tip over max",
+			policy: FeePolicy{Tip: big.NewInt(10), MaxTipCap: big.NewInt(5), BaseFeeMultiplier: 3},
+			tip:    5,
+			feeCap: 3_000_000_005,
+		},
+		{
+			name:   "fee cap bounds",
+			policy: FeePolicy{MaxFeeCap: big.NewInt(1_500_000_000)},
+			tip:    1,
+			feeCap: 1_500_000_000,
+		},
+		{
+			name:   "tip over max fee cap",
+			policy: FeePolicy{Tip: big.NewInt(2_000_000_000), MaxFeeCap: big.NewInt(1_500_000_000)},
+			tip:    1_500_000_000,
+			feeCap: 1_500_000_000,
+		},
+		{
+			name:   "min tip over max fee cap",
+			policy: FeePolicy{MaxFeeCap: big.NewInt(1_500_000_000)},
+			setup: func(cfg *txmgr.Config) {
+				cfg.MinTipCap.Store(big.NewInt(2_000_000_000))
+			},
+			fails: true,
+		},
+		{
+			name:       "blob multiplier",
+			policy:     FeePolicy{MinFeeCap: big.NewInt(5_000_000_000), BlobFeeCapMultiplier: 7},
+			blobs:      blob,
+			tip:        1,
+			feeCap:     5_000_000_000,
+			blobFeeCap: 7,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			txmanager, _, addr := newTestTxMgr(t, WithFeePolicy(test.policy))
+			if test.setup != nil {
+				test.setup(txmanager.cfg)
+			}
+			tx, err := txmanager.craftTx(context.Background(), txmgr.TxCandidate{To: &addr, GasLimit: 21000, Blobs: test.blobs})
+			if test.fails {
+				if err == nil {
+					t.Fatalf("Crafted tx with tip %v over fee cap %v", tx.GasTipCap(), tx.GasFeeCap())
+				}
+				return
+			} else if err != nil {
+				t.Fatal(err)
+			}
+			if tx.GasTipCap().Int64() != test.tip || tx.GasFeeCap().Int64() != test.feeCap {
+				t.Fatalf("Wrong caps. Have tip %v, fee cap %v, want %v, %v", tx.GasTipCap(), tx.GasFeeCap(), test.tip, test.feeCap)
+			}
+			if test.blobs != nil && tx.BlobGasFeeCap().Int64() != test.blobFeeCap {
+				t.Fatalf("Wrong blob fee cap. Have %v, want %v", tx.BlobGasFeeCap(), test.blobFeeCap)
+			}
+		})
+	}
+}
+
+func TestFeeLimit(t *testing.T) {
+	genesis := time.Unix(1_700_000_000, 0)
+	clk := clock.NewDeterministicClock(genesis.Add(2 * 12 * time.Second))
+	txmanager, gateway, addr := newTestTxMgr(t, WithSlotClock(newSlotClock(t, genesis, clk)), WithFeeSafetyMultiplier(2))
+	txmanager.cfg.FeeLimitMultiplier.Store(1)
+	gateway.AddSlot(luban.SlotInfo{Slot: 6, GasAvailable: 30_000_000, BlobsAvailable: 6})
+	gateway.SetDefaultFee(lubantest.Fee{GasFee: 10, BlobGasFee: 10})
+
+	_, err := txmanager.Send(context.Background(), txmgr.TxCandidate{To: &addr, GasLimit: 21000})
+	if !errors.Is(err, ErrFeeLimit) {
+		t.Fatalf("Expected %v, have %v", ErrFeeLimit, err)
+	}
+	if len(gateway.Reservations()) != 0 {
+		t.Fatal("Blockspace was reserved over the fee limit")
+	}
+
+	// Raised cap under the threshold is allowed. Threshold is set over RPC.
+	api := txmanager.API()
+	srv := rpc.NewServer()
+	if err := srv.RegisterName(api.Namespace, api.Service); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(srv.Stop)
+	rpcClient := rpc.DialInProc(srv)
+	t.Cleanup(rpcClient.Close)
+	threshold := big.NewInt(10_000_000_000)
+	if err := rpcClient.Call(nil, "txmgr_setFeeThreshold", threshold); err != nil {
+		t.Fatal(err)
+	}
+	var have *big.Int
+	if err := rpcClient.Call(&have, "txmgr_getFeeThreshold"); err != nil {
+		t.Fatal(err)
+	}
+	if have.Cmp(threshold) != 0 {
+		t.Fatalf("Wrong fee threshold. Have %v, want %v", have, threshold)
+	}
+	if _, err := txmanager.Send(context.Background(), txmgr.TxCandidate{To: &addr, GasLimit: 21000}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestChainConfigByID(t *testing.T) {
+	config, err := ChainConfigByID(params.HoleskyChainConfig.ChainID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.PragueTime == nil || !config.IsPrague(big.NewInt(1), 1_740_434_112) {
+		t.Fatal("Holesky config has no Prague")
+	}
+	if params.HoleskyChainConfig.PragueTime != nil {
+		t.Fatal("Geth config was modified")
+	}
+	if _, err := ChainConfigByID(big.NewInt(7028081469)); !errors.Is(err, ErrUnknownChain) {
+		t.Fatalf("Expected %v, have %v", ErrUnknownChain, err)
+	}
+
+	// Unknown chain isn't retried
+	txmanager, _, addr := newTestTxMgr(t, WithChainConfig(nil))
+	start := time.Now()
+	if _, err := txmanager.Send(context.Background(), txmgr.TxCandidate{To: &addr, GasLimit: 21000}); !errors.Is(err, ErrUnknownChain) {
+		t.Fatalf("Expected %v, have %v", ErrUnknownChain, err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("Unknown chain was retried for %v", elapsed)
+	}
+}
+
+func TestBlobSchedule(t *testing.T) {
+	config := devChainConfig(big.NewInt(1337))
+	excess := uint64(10 * params.BlobTxBlobGasPerBlob)
+	parent := &types.Header{Number: big.NewInt(1), BaseFee: big.NewInt(1_000_000_000), GasLimit: 30_000_000, ExcessBlobGas: &excess}
+
+	devnet := DefaultBlobSchedule()
+	devnet.Cancun = BlobConfig{Target: 1, Max: 2, UpdateFraction: 1112826}
+	_, blobFee := calcBaseFees(config, DefaultBlobSchedule(), parent, 12)
+	_, devnetBlobFee := calcBaseFees(config, devnet, parent, 12)
+	if want := devnet.Cancun.BlobFee(excess - params.BlobTxBlobGasPerBlob); devnetBlobFee.Cmp(want) != 0 || devnetBlobFee.Cmp(blobFee) <= 0 {
+		t.Fatalf("Custom blob schedule not applied. Have %v, want %v", devnetBlobFee, want)
+	}
+
+	invalid := DefaultBlobSchedule()
+	invalid.Prague.UpdateFraction = 0
+	if err := invalid.Validate(); err == nil {
+		t.Fatal("Zero update fraction is valid")
+	}
+}
+
+// countingBeacon counts requests of chain spec
+type countingBeacon struct {
+	fakeBeacon
+	specs atomic.Int32
+}
+
+func (b *countingBeacon) Spec(ctx context.Context) (*beacon.Spec, error) {
+	b.specs.Add(1)
+	return b.fakeBeacon.Spec(ctx)
+}
+
+func TestSlotClockCached(t *testing.T) {
+	txmanager, _, _ := newTestTxMgr(t)
+	bn := &countingBeacon{}
+	txmanager.beacon = bn
+
+	for range 3 {
+		if _, err := txmanager.slotClock(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if have := bn.specs.Load(); have != 1 {
+		t.Fatalf("Slot clock fetched %d times", have)
+	}
+}
+
+func TestCraftTxPreCancun(t *testing.T) {
+	config := devChainConfig(big.NewInt(7028081469))
+	config.CancunTime = nil
+	txmanager, _, addr := newTestTxMgr(t, WithChainConfig(config))
+
+	tx, err := txmanager.craftTx(context.Background(), txmgr.TxCandidate{To: &addr, GasLimit: 21000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tx.GasFeeCap().Sign() <= 0 {
+		t.Fatal("No fee cap")
+	}
+	if _, err := txmanager.craftTx(context.Background(), txmgr.TxCandidate{To: &addr, Blobs: []*eth.Blob{{}}}); !errors.Is(err, ErrBlobsBeforeCancun) {
+		t.Fatalf("Expected %v, have %v", ErrBlobsBeforeCancun, err)
+	}
+}
+
+func TestSendConfirmations(t *testing.T) {
+	txmanager, gateway, addr := newTestTxMgr(t, WithInclusionConfig(InclusionConfig{
+		Timeout: 200 * time.Millisecond,
+	}))
+	txmanager.cfg.ReceiptQueryInterval = 10 * time.Millisecond
+	// Receipt is in the tip block, so it never gets 2 confirmations
+	txmanager.cfg.NumConfirmations = 2
+	gateway.AddSlot(luban.SlotInfo{Slot: 3, GasAvailable: 30_000_000, BlobsAvailable: 6})
+
+	_, err := txmanager.Send(context.Background(), txmgr.TxCandidate{To: &addr, GasLimit: 21000})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected %v, have %v", context.DeadlineExceeded, err)
+	}
+	if len(gateway.Reservations()) != 1 {
+		t.Fatal("Tx wasn't preconfed")
+	}
+}
+
+// slowBeacon advances head by a slot in slot duration, while it's awaited
+type slowBeacon struct {
+	fakeBeacon
+	slotDuration time.Duration
+}
+
+func (b *slowBeacon) WaitForHead(ctx context.Context, slot uint64) (uint64, error) {
+	b.mu.Lock()
+	head := b.head
+	b.mu.Unlock()
+	if slot > head {
+		select {
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		case <-time.After(time.Duration(slot-head) * b.slotDuration):
+		}
+	}
+	return b.fakeBeacon.WaitForHead(ctx, slot)
+}
+
+// tipBackend advances tip on every request of block number
+type tipBackend struct {
+	*fakeBackend
+	tip atomic.Uint64
+}
+
+func (b *tipBackend) BlockNumber(ctx context.Context) (uint64, error) {
+	return b.header.Number.Uint64() + b.tip.Add(1), nil
+}
+
+// flakyBackend fails the first request of the latest header
+type flakyBackend struct {
+	*fakeBackend
+	failed atomic.Bool
+}
+
+func (b *flakyBackend) HeaderByNumber(ctx context.Context, num *big.Int) (*types.Header, error) {
+	if num == nil && !b.failed.Swap(true) {
+		return nil, errors.New("connection reset")
+	}
+	return b.fakeBackend.HeaderByNumber(ctx, num)
+}
+
+func TestSendPriceForSlotRetried(t *testing.T) {
+	txmanager, gateway, addr := newTestTxMgr(t)
+	backend := &flakyBackend{fakeBackend: newFakeBackend(gateway)}
+	txmanager.backend = backend
+	for slot := uint64(2); slot < 8; slot++ {
+		gateway.AddSlot(luban.SlotInfo{Slot: slot, GasAvailable: 30_000_000, BlobsAvailable: 6})
+	}
+
+	if _, err := txmanager.Send(context.Background(), txmgr.TxCandidate{To: &addr, GasLimit: 21000}); err != nil {
+		t.Fatal(err)
+	}
+	if !backend.failed.Load() {
+		t.Fatal("Tx wasn't priced for slot")
+	}
+}
+
+func TestSendConfirmationsDistantSlot(t *testing.T) {
+	txmanager, gateway, addr := newTestTxMgr(t, WithInclusionConfig(InclusionConfig{
+		Timeout: 150 * time.Millisecond,
+		Grace:   time.Second,
+	}))
+	// Reaching the target slot takes longer than the inclusion timeout
+	txmanager.beacon = &slowBeacon{fakeBeacon: fakeBeacon{head: 1}, slotDuration: 50 * time.Millisecond}
+	txmanager.backend = &tipBackend{fakeBackend: newFakeBackend(gateway)}
+	txmanager.cfg.ReceiptQueryInterval = 10 * time.Millisecond
+	txmanager.cfg.NumConfirmations = 3
+	gateway.AddSlot(luban.SlotInfo{Slot: 6, GasAvailable: 30_000_000, BlobsAvailable: 6})
+
+	res, err := txmanager.SendWithResult(context.Background(), txmgr.TxCandidate{To: &addr, GasLimit: 21000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Reservation.TargetSlot() != 6 || res.Receipt == nil {
+		t.Fatalf("Wrong result: %+v", res)
+	}
+}
+
+func TestSendAsyncQueue(t *testing.T) {
+	txmanager, gateway, addr := newTestTxMgr(t)
+	for slot := uint64(2); slot < 12; slot++ {
+		gateway.AddSlot(luban.SlotInfo{Slot: slot, GasAvailable: 30_000_000, BlobsAvailable: 6})
+	}
+	gateway.SetDefaultFee(lubantest.Fee{GasFee: 10, BlobGasFee: 10})
+
+	ch := make(chan txmgr.SendResponse, 1)
+	txmanager.SendAsync(context.Background(), txmgr.TxCandidate{To: &addr, GasLimit: 21000}, ch)
+	if resp := <-ch; resp.Err != nil || resp.Receipt == nil || resp.Nonce != 0 {
+		t.Fatalf("Wrong async response: %+v", resp)
+	}
+
+	queue := txmgr.NewQueue[int](context.Background(), txmanager, 2)
+	receipts := make(chan txmgr.TxReceipt[int], 2)
+	for i := 0; i < 2; i++ {
+		queue.Send(i, txmgr.TxCandidate{To: &addr, GasLimit: 21000}, receipts)
+	}
+	if err := queue.Wait(); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if r := <-receipts; r.Err != nil {
+			t.Fatalf("Queued tx %d failed: %v", r.ID, r.Err)
+		}
+	}
+
+	txmanager.Close()
+	if !txmanager.IsClosed() {
+		t.Fatal("Tx manager is not closed")
+	}
+	if _, err := txmanager.Send(context.Background(), txmgr.TxCandidate{To: &addr}); !errors.Is(err, txmgr.ErrClosed) {
+		t.Fatalf("Expected %v, have %v", txmgr.ErrClosed, err)
+	}
+	txmanager.SendAsync(context.Background(), txmgr.TxCandidate{To: &addr}, ch)
+	if resp := <-ch; !errors.Is(resp.Err, txmgr.ErrClosed) {
+		t.Fatalf("Expected %v, have %v", txmgr.ErrClosed, resp.Err)
+	}
+}
+
+func TestSuggestGasPriceCaps(t *testing.T) {
+	prague := uint64(0)
+	config := devChainConfig(big.NewInt(7028081469))
+	config.PragueTime = &prague
+	txmanager, _, _ := newTestTxMgr(t, WithChainConfig(config))
+	txmanager.cfg.MinTipCap.Store(big.NewInt(5))
+
+	tip, baseFee, blobFee, err := txmanager.SuggestGasPriceCaps(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tip.Int64() != 5 {
+		t.Fatalf("Min tip cap not enforced. Have %v, want 5", tip)
+	}
+	if baseFee.Int64() != 1_000_000_000 || blobFee == nil {
+		t.Fatalf("Wrong fees. Have base %v, blob %v", baseFee, blobFee)
+	}
+
+	// Blob base fee follows the blob schedule, as fee caps of crafted
+	// transactions do
+	excess := uint64(400 * params.BlobTxBlobGasPerBlob)
+	txmanager.backend.(*fakeBackend).header.ExcessBlobGas = &excess
+	_, _, blobFee, err = txmanager.SuggestGasPriceCaps(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := PragueBlobConfig.BlobFee(excess - 6*params.BlobTxBlobGasPerBlob); blobFee.Cmp(want) != 0 {
+		t.Fatalf("Wrong blob base fee. Have %v, want %v", blobFee, want)
+	}
+}
+
+// stuckBeacon doesn't respond, until request is cancelled
+type stuckBeacon struct {
+	fakeBeacon
+}
+
+func (b *stuckBeacon) HeadSlot(ctx context.Context) (uint64, error) {
+	<-ctx.Done()
+	return 0, ctx.Err()
+}
+
+func (b *stuckBeacon) Genesis(ctx context.Context) (*beacon.Genesis, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestBeaconTimeout(t *testing.T) {
+	txmanager, _, _ := newTestTxMgr(t)
+	txmanager.beacon = &stuckBeacon{}
+	txmanager.cfg.NetworkTimeout = 50 * time.Millisecond
+
+	if _, err := txmanager.headSlot(context.Background()); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected %v, have %v", context.DeadlineExceeded, err)
+	}
+
+	if _, err := txmanager.slotClock(context.Background()); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected %v, have %v", context.DeadlineExceeded, err)
+	}
+}
+
 func TestTxmgr(t *testing.T) {
-	keyStr, notSet := os.Lookupenv("TEST_LUBAN_KEY")
-	if notSet {
-		return
+	keyStr, ok := os.LookupEnv("TEST_LUBAN_KEY")
+	if !ok {
+		t.Skip("TEST_LUBAN_KEY is not set")
 	}
 	key, _ := crypto.HexToECDSA(keyStr)
 	l := testlog.Logger(t, log.LevelTrace)
@@ -43,13 +1345,14 @@ func TestTxmgr(t *testing.T) {
 	}
 	rpc := ethclient.NewClient(cl)
 
-	preconfer, err := client.NewClient("https://gateway.taiyi-devnet-0.preconfs.org", key)
+	preconfer, err := client.NewClient("https://gateway.taiyi-devnet-0.preconfs.org", client.NewPrivateKeySigner(key))
 	if err != nil {
 		panic(err)
 	}
 
 	blobs := []*eth.Blob{&eth.Blob{}}
-	txmanager := NewPreconfTxMgr(l, rpc, cfg, preconfer, "https://bn.bootnode-1.taiyi-devnet-0.preconfs.org")
+	bn := beacon.NewClient("https://bn.bootnode-1.taiyi-devnet-0.preconfs.org", beacon.WithTimeout(cfg.NetworkTimeout))
+	txmanager := NewPreconfTxMgr(l, rpc, cfg, preconfer, bn, WithChainConfig(devChainConfig(chainId)))
 
 	cand := txmgr.TxCandidate{Blobs: blobs, To: &addr}
 	_, err = txmanager.Send(context.Background(), cand)
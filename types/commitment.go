@@ -0,0 +1,132 @@
+package types
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/google/uuid"
+)
+
+var ErrCommitmentSigner = errors.New("commitment is not signed by expected gateway")
+
+// Commitment is a signature from the gateway, returned on transaction
+// submission, promising to include the transaction in the reserved slot.
+type Commitment struct {
+	R       common.Hash
+	S       common.Hash
+	V       uint64
+	YParity uint64
+}
+
+// ParseCommitment parses the hex encoded fields of the commitment as returned
+// by the gateway.
+func ParseCommitment(r, s, v, yParity string) (Commitment, error) {
+	rHash, err := decodeWord(r)
+	if err != nil {
+		return Commitment{}, fmt.Errorf("invalid commitment r: %w", err)
+	}
+	sHash, err := decodeWord(s)
+	if err != nil {
+		return Commitment{}, fmt.Errorf("invalid commitment s: %w", err)
+	}
+	vInt, err := hexutil.DecodeUint64(v)
+	if err != nil {
+		return Commitment{}, fmt.Errorf("invalid commitment v: %w", err)
+	}
+	parity, err := hexutil.DecodeUint64(yParity)
+	if err != nil {
+		return Commitment{}, fmt.Errorf("invalid commitment yParity: %w", err)
+	}
+	if parity > 1 {
+		return Commitment{}, fmt.Errorf("invalid commitment yParity %d", parity)
+	}
+	return Commitment{
+		R:       rHash,
+		S:       sHash,
+		V:       vInt,
+		YParity: parity,
+	}, nil
+}
+
+// Gateway may strip leading zeroes from r and s, so we can't use hexutil.Decode
+func decodeWord(s string) (common.Hash, error) {
+	if !has0xPrefix(s) {
+		return common.Hash{}, hexutil.ErrMissingPrefix
+	}
+	s = s[2:]
+	if len(s)%2 == 1 {
+		s = "0" + s
+	}
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if len(b) > common.HashLength {
+		return common.Hash{}, fmt.Errorf("value is %d bytes long, expected at most %d", len(b), common.HashLength)
+	}
+	return common.BytesToHash(b), nil
+}
+
+func has0xPrefix(s string) bool {
+	return len(s) >= 2 && s[0] == '0' && (s[1] == 'x' || s[1] == 'X')
+}
+
+// NewCommitment splits 65 byte [R || S || V] signature into commitment.
+func NewCommitment(sig []byte) (Commitment, error) {
+	if len(sig) != crypto.SignatureLength {
+		return Commitment{}, fmt.Errorf("invalid signature length %d", len(sig))
+	}
+	parity := uint64(sig[crypto.RecoveryIDOffset])
+	if parity >= 27 {
+		parity -= 27
+	}
+	if parity > 1 {
+		return Commitment{}, fmt.Errorf("invalid signature recovery id %d", sig[crypto.RecoveryIDOffset])
+	}
+	return Commitment{
+		R:       common.BytesToHash(sig[:32]),
+		S:       common.BytesToHash(sig[32:64]),
+		V:       parity,
+		YParity: parity,
+	}, nil
+}
+
+// Signature returns commitment in 65 byte [R || S || V] format with V being 0 or 1.
+func (c *Commitment) Signature() []byte {
+	sig := make([]byte, 0, crypto.SignatureLength)
+	sig = append(sig, c.R.Bytes()...)
+	sig = append(sig, c.S.Bytes()...)
+	return append(sig, byte(c.YParity))
+}
+
+// Signer recovers address of the gateway, which signed commitment for the
+// transaction submitted under reqId.
+func (c *Commitment) Signer(reqId uuid.UUID, tx *Transaction) (common.Address, error) {
+	r, s := new(big.Int).SetBytes(c.R.Bytes()), new(big.Int).SetBytes(c.S.Bytes())
+	if !crypto.ValidateSignatureValues(byte(c.YParity), r, s, true) {
+		return common.Address{}, errors.New("invalid commitment signature values")
+	}
+	pub, err := crypto.SigToPub(SubmitTxDigest(reqId, tx).Bytes(), c.Signature())
+	if err != nil {
+		return common.Address{}, fmt.Errorf("failed to recover commitment signer: %w", err)
+	}
+	return crypto.PubkeyToAddress(*pub), nil
+}
+
+// Verify checks that commitment for the transaction submitted under reqId is
+// signed by gateway.
+func (c *Commitment) Verify(reqId uuid.UUID, tx *Transaction, gateway common.Address) error {
+	signer, err := c.Signer(reqId, tx)
+	if err != nil {
+		return err
+	}
+	if signer != gateway {
+		return fmt.Errorf("%w: have %v, want %v", ErrCommitmentSigner, signer, gateway)
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package types
+
+import (
+	"errors"
+
+	u256 "github.com/holiman/uint256"
+)
+
+// ErrPriceOverflow is returned, when deposit or tip doesn't fit uint256
+var ErrPriceOverflow = errors.New("price overflows uint256")
+
+// ComputeDeposit returns deposit of blockspace reservation as defined by the
+// spec:
+//
+//	{ gas_limit * gas_fee + blob_count * blob_gas_fee } * 0.5
+func ComputeDeposit(gasLimit uint64, blobCount uint32, gasFee, blobGasFee *u256.Int) (*u256.Int, error) {
+	return halfCost(gasLimit, blobCount, gasFee, blobGasFee)
+}
+
+// ComputeTip returns tip of blockspace reservation as defined by the spec,
+// which is the same as deposit:
+//
+//	{ gas_limit * gas_fee + blob_count * blob_gas_fee } * 0.5
+func ComputeTip(gasLimit uint64, blobCount uint32, gasFee, blobGasFee *u256.Int) (*u256.Int, error) {
+	return halfCost(gasLimit, blobCount, gasFee, blobGasFee)
+}
+
+func halfCost(gasLimit uint64, blobCount uint32, gasFee, blobGasFee *u256.Int) (*u256.Int, error) {
+	gas, overflow := new(u256.Int).MulOverflow(u256.NewInt(gasLimit), gasFee)
+	if overflow {
+		return nil, ErrPriceOverflow
+	}
+	blob, overflow := new(u256.Int).MulOverflow(u256.NewInt(uint64(blobCount)), blobGasFee)
+	if overflow {
+		return nil, ErrPriceOverflow
+	}
+	cost, overflow := gas.AddOverflow(gas, blob)
+	if overflow {
+		return nil, ErrPriceOverflow
+	}
+	return cost.Rsh(cost, 1), nil
+}
@@ -0,0 +1,50 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var (
+	ErrUnsignedReservation = errors.New("reservation is not signed by gateway")
+	ErrReservationSigner   = errors.New("reservation is not signed by expected gateway")
+)
+
+// IsSigned reports whether gateway signed the reservation.
+func (r *ReserveBlockSpaceResponse) IsSigned() bool {
+	return len(r.Signature) > 0
+}
+
+// Signer recovers address of the gateway, which signed reservation of req.
+func (r *ReserveBlockSpaceResponse) Signer(req *ReserveBlockSpaceRequest) (common.Address, error) {
+	if !r.IsSigned() {
+		return common.Address{}, ErrUnsignedReservation
+	}
+	if len(r.Signature) != crypto.SignatureLength {
+		return common.Address{}, fmt.Errorf("invalid reservation signature length %d", len(r.Signature))
+	}
+	sig := append([]byte{}, r.Signature...)
+	if sig[crypto.RecoveryIDOffset] >= 27 {
+		sig[crypto.RecoveryIDOffset] -= 27
+	}
+	pub, err := crypto.SigToPub(ReservationDigest(r.RequestId, req).Bytes(), sig)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("failed to recover reservation signer: %w", err)
+	}
+	return crypto.PubkeyToAddress(*pub), nil
+}
+
+// Verify checks that reservation of req is signed by gateway.
+func (r *ReserveBlockSpaceResponse) Verify(req *ReserveBlockSpaceRequest, gateway common.Address) error {
+	signer, err := r.Signer(req)
+	if err != nil {
+		return err
+	}
+	if signer != gateway {
+		return fmt.Errorf("%w: have %v, want %v", ErrReservationSigner, signer, gateway)
+	}
+	return nil
+}
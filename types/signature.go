@@ -0,0 +1,105 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/google/uuid"
+)
+
+var ErrSignatureMismatch = errors.New("signature doesn't match signer address")
+
+// LubanSignature is the value of x-luban-signature header.
+//
+// Blockspace reservations are signed as "<address>:0x<signature>", while
+// submitted transactions are signed with bare "0x<signature>".
+type LubanSignature struct {
+	// Address of the signer. It is nil for bare signatures.
+	Address *common.Address
+	// Signature is 65 byte [R || S || V] signature, V being 0 or 1.
+	Signature []byte
+}
+
+// NewReserveSignature makes signature of blockspace reservation, which
+// embeds signer address.
+func NewReserveSignature(addr common.Address, sig []byte) LubanSignature {
+	return LubanSignature{Address: &addr, Signature: sig}
+}
+
+// NewSubmitSignature makes bare signature of submitted transaction.
+func NewSubmitSignature(sig []byte) LubanSignature {
+	return LubanSignature{Signature: sig}
+}
+
+// ParseLubanSignature parses x-luban-signature header in either format.
+func ParseLubanSignature(header string) (LubanSignature, error) {
+	var s LubanSignature
+
+	sigStr := header
+	if addrStr, rest, found := strings.Cut(header, ":"); found {
+		if !common.IsHexAddress(addrStr) {
+			return LubanSignature{}, fmt.Errorf("invalid signer address %q", addrStr)
+		}
+		addr := common.HexToAddress(addrStr)
+		s.Address = &addr
+		sigStr = rest
+	}
+
+	sig, err := hexutil.Decode(sigStr)
+	if err != nil {
+		return LubanSignature{}, fmt.Errorf("invalid signature: %w", err)
+	}
+	if len(sig) != crypto.SignatureLength {
+		return LubanSignature{}, fmt.Errorf("invalid signature length %d", len(sig))
+	}
+	s.Signature = sig
+	return s, nil
+}
+
+func (s LubanSignature) String() string {
+	if s.Address == nil {
+		return hexutil.Encode(s.Signature)
+	}
+	return fmt.Sprintf("%v:%s", *s.Address, hexutil.Encode(s.Signature))
+}
+
+// Recover returns signer of the digest. If signature embeds address, recovered
+// signer is checked against it.
+func (s *LubanSignature) Recover(digest common.Hash) (common.Address, error) {
+	pub, err := crypto.SigToPub(digest.Bytes(), s.Signature)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("failed to recover signer: %w", err)
+	}
+	signer := crypto.PubkeyToAddress(*pub)
+	if s.Address != nil && *s.Address != signer {
+		return common.Address{}, fmt.Errorf("%w: signed by %v, not %v", ErrSignatureMismatch, signer, *s.Address)
+	}
+	return signer, nil
+}
+
+// VerifyReserveBlockspace checks x-luban-signature header of blockspace
+// reservation and returns the signer.
+func VerifyReserveBlockspace(req *ReserveBlockSpaceRequest, header string) (common.Address, error) {
+	sig, err := ParseLubanSignature(header)
+	if err != nil {
+		return common.Address{}, err
+	}
+	if sig.Address == nil {
+		return common.Address{}, errors.New("reservation signature must embed signer address")
+	}
+	return sig.Recover(req.Digest())
+}
+
+// VerifySubmitTx checks x-luban-signature header of transaction submitted
+// under reqId and returns the signer.
+func VerifySubmitTx(reqId uuid.UUID, tx *Transaction, header string) (common.Address, error) {
+	sig, err := ParseLubanSignature(header)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return sig.Recover(SubmitTxDigest(reqId, tx))
+}
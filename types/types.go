@@ -18,10 +18,12 @@ type (
 	SlotInfo                 = internal.SlotInfo
 	ReserveBlockSpaceRequest internal.ReserveBlockSpaceRequest
 
+	// ReserveBlockSpaceResponse is a receipt of blockspace reservation
 	ReserveBlockSpaceResponse struct {
 		RequestId uuid.UUID
-		// TODO: figure out type for signature
-		Signature string
+		// Signature of the gateway over [ReservationDigest]. It is nil, if
+		// gateway returned bare request id.
+		Signature hexutil.Bytes
 	}
 )
 
@@ -33,7 +35,9 @@ func appendUint256(o binary.AppendByteOrder, to []byte, u256 hexutil.U256) []byt
 	return to
 }
 
-func (req *ReserveBlockSpaceRequest) Digest() common.Hash {
+// DigestData returns data, which [ReserveBlockSpaceRequest.Digest] is keccak256
+// of.
+func (req *ReserveBlockSpaceRequest) DigestData() []byte {
 	var digest []byte
 	le := binary.LittleEndian
 
@@ -44,7 +48,11 @@ func (req *ReserveBlockSpaceRequest) Digest() common.Hash {
 	digest = appendUint256(le, digest, req.Tip)
 	digest = le.AppendUint64(digest, uint64(req.BlobCount))
 
-	return crypto.Keccak256Hash(digest)
+	return digest
+}
+
+func (req *ReserveBlockSpaceRequest) Digest() common.Hash {
+	return crypto.Keccak256Hash(req.DigestData())
 }
 
 // https://docs.rs/uuid/latest/uuid/struct.Uuid.html#method.to_bytes_le
@@ -57,12 +65,27 @@ func appendUuidToLe(to []byte, id uuid.UUID) []byte {
 	return to
 }
 
-func SubmitTxDigest(reqId uuid.UUID, tx *Transaction) common.Hash {
+// ReservationDigest is a digest of reservation receipt signed by the gateway
+func ReservationDigest(reqId uuid.UUID, req *ReserveBlockSpaceRequest) common.Hash {
+	var digest []byte
+
+	digest = appendUuidToLe(digest, reqId)
+	digest = append(digest, req.Digest().Bytes()...)
+
+	return crypto.Keccak256Hash(digest)
+}
+
+// SubmitTxDigestData returns data, which [SubmitTxDigest] is keccak256 of.
+func SubmitTxDigestData(reqId uuid.UUID, tx *Transaction) []byte {
 	var digest []byte
 
 	// https://github.com/lu-bann/taiyi/blob/0c9ebba9010aa097e6a1f4017fb8262a0ee64705/crates/primitives/src/preconf_request.rs#L100-L103
 	digest = appendUuidToLe(digest, reqId)
 	digest = append(digest, tx.Hash().Bytes()...)
 
-	return crypto.Keccak256Hash(digest)
+	return digest
+}
+
+func SubmitTxDigest(reqId uuid.UUID, tx *Transaction) common.Hash {
+	return crypto.Keccak256Hash(SubmitTxDigestData(reqId, tx))
 }
@@ -1,6 +1,7 @@
 package types
 
 import (
+	"errors"
 	"math/big"
 	"testing"
 
@@ -45,3 +46,140 @@ func TestReserveBlockSpaceDigest(t *testing.T) {
 		t.Fatalf("Wrong reserve blockspace digest. Have %v, want %v", have, want)
 	}
 }
+
+func TestComputeDeposit(t *testing.T) {
+	// { 21000 * 10 + 2 * 7 } * 0.5
+	deposit, err := ComputeDeposit(21000, 2, u256.NewInt(10), u256.NewInt(7))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deposit.Uint64() != 105007 {
+		t.Fatalf("Wrong deposit. Have %v, want 105007", deposit)
+	}
+	tip, err := ComputeTip(21000, 2, u256.NewInt(10), u256.NewInt(7))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !tip.Eq(deposit) {
+		t.Fatalf("Tip %v differs from deposit %v", tip, deposit)
+	}
+
+	maxFee := new(u256.Int).SetAllOne()
+	if _, err := ComputeDeposit(2, 0, maxFee, u256.NewInt(0)); !errors.Is(err, ErrPriceOverflow) {
+		t.Fatalf("Expected %v, have %v", ErrPriceOverflow, err)
+	}
+	if _, err := ComputeTip(1, 1, maxFee, u256.NewInt(1)); !errors.Is(err, ErrPriceOverflow) {
+		t.Fatalf("Expected %v, have %v", ErrPriceOverflow, err)
+	}
+}
+
+func TestCommitmentVerify(t *testing.T) {
+	id, _ := uuid.Parse("a1a2a3a4-b1b2-c1c2-d1d2-d3d4d5d6d7d8")
+	key, _ := crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	gateway := crypto.PubkeyToAddress(key.PublicKey)
+	tx := types.NewTx(&types.LegacyTx{Nonce: 1, Gas: 1, GasPrice: big.NewInt(2)})
+
+	sig, err := crypto.Sign(SubmitTxDigest(id, tx).Bytes(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := hexutil.Encode
+	commitment, err := ParseCommitment(
+		c(sig[:32]), c(sig[32:64]), hexutil.EncodeUint64(uint64(sig[64])+27), hexutil.EncodeUint64(uint64(sig[64])),
+	)
+	if err != nil {
+		t.Fatalf("Failed to parse commitment: %v", err)
+	}
+	if err := commitment.Verify(id, tx, gateway); err != nil {
+		t.Fatalf("Commitment verification failed: %v", err)
+	}
+	if err := commitment.Verify(uuid.New(), tx, gateway); !errors.Is(err, ErrCommitmentSigner) {
+		t.Fatalf("Commitment for other request id verified. Have %v, want %v", err, ErrCommitmentSigner)
+	}
+}
+
+func TestReservationReceiptVerify(t *testing.T) {
+	id, _ := uuid.Parse("a1a2a3a4-b1b2-c1c2-d1d2-d3d4d5d6d7d8")
+	key, _ := crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	gateway := crypto.PubkeyToAddress(key.PublicKey)
+	req := &ReserveBlockSpaceRequest{TargetSlot: 10, GasLimit: 21000, BlobCount: 1}
+
+	sig, err := crypto.Sign(ReservationDigest(id, req).Bytes(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig[crypto.RecoveryIDOffset] += 27
+	receipt := ReserveBlockSpaceResponse{RequestId: id, Signature: sig}
+	if err := receipt.Verify(req, gateway); err != nil {
+		t.Fatalf("Receipt verification failed: %v", err)
+	}
+	other := *req
+	other.GasLimit++
+	if err := receipt.Verify(&other, gateway); !errors.Is(err, ErrReservationSigner) {
+		t.Fatalf("Receipt of other request verified. Have %v, want %v", err, ErrReservationSigner)
+	}
+	unsigned := ReserveBlockSpaceResponse{RequestId: id}
+	if err := unsigned.Verify(req, gateway); !errors.Is(err, ErrUnsignedReservation) {
+		t.Fatalf("Expected %v, have %v", ErrUnsignedReservation, err)
+	}
+}
+
+func TestParseCommitmentShortWords(t *testing.T) {
+	commitment, err := ParseCommitment("0x1", "0xabc", "0x1c", "0x1")
+	if err != nil {
+		t.Fatalf("Failed to parse commitment: %v", err)
+	}
+	if have, want := commitment.R, common.HexToHash("0x01"); have != want {
+		t.Fatalf("Wrong r. Have %v, want %v", have, want)
+	}
+	if have, want := commitment.S, common.HexToHash("0x0abc"); have != want {
+		t.Fatalf("Wrong s. Have %v, want %v", have, want)
+	}
+	if _, err := ParseCommitment("0x1", "0x1", "0x0", "0x2"); err == nil {
+		t.Fatal("Commitment with invalid yParity parsed")
+	}
+}
+
+func TestLubanSignature(t *testing.T) {
+	key, _ := crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	addr := crypto.PubkeyToAddress(key.PublicKey)
+	req := ReserveBlockSpaceRequest{GasLimit: 21000, TargetSlot: 23}
+
+	sig, _ := crypto.Sign(req.Digest().Bytes(), key)
+	header := NewReserveSignature(addr, sig).String()
+	if want := addr.Hex() + ":" + hexutil.Encode(sig); header != want {
+		t.Fatalf("Wrong reserve header. Have %v, want %v", header, want)
+	}
+	if signer, err := VerifyReserveBlockspace(&req, header); err != nil || signer != addr {
+		t.Fatalf("Failed to verify reserve header. Have (%v, %v), want %v", signer, err, addr)
+	}
+
+	other := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	forged := NewReserveSignature(other, sig).String()
+	if _, err := VerifyReserveBlockspace(&req, forged); !errors.Is(err, ErrSignatureMismatch) {
+		t.Fatalf("Forged reserve header verified. Have %v, want %v", err, ErrSignatureMismatch)
+	}
+	if _, err := VerifyReserveBlockspace(&req, hexutil.Encode(sig)); err == nil {
+		t.Fatal("Reserve header without address verified")
+	}
+
+	id := uuid.New()
+	tx := types.NewTx(&types.LegacyTx{Nonce: 1, Gas: 1, GasPrice: big.NewInt(2)})
+	sig, _ = crypto.Sign(SubmitTxDigest(id, tx).Bytes(), key)
+	header = NewSubmitSignature(sig).String()
+	if want := hexutil.Encode(sig); header != want {
+		t.Fatalf("Wrong submit header. Have %v, want %v", header, want)
+	}
+	if signer, err := VerifySubmitTx(id, tx, header); err != nil || signer != addr {
+		t.Fatalf("Failed to verify submit header. Have (%v, %v), want %v", signer, err, addr)
+	}
+	if signer, _ := VerifySubmitTx(uuid.New(), tx, header); signer == addr {
+		t.Fatal("Submit header verified for other request id")
+	}
+
+	for _, header := range []string{"", "0x1234", "nothex:0x00", addr.Hex() + ":" + hexutil.Encode(sig[:64])} {
+		if _, err := ParseLubanSignature(header); err == nil {
+			t.Fatalf("Malformed header %q parsed", header)
+		}
+	}
+}